@@ -1,7 +1,10 @@
 package hanautil
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -11,7 +14,10 @@ import (
 
 // GetVersion returns the version of the the HANA database and an error.
 func (h *HanaUtilClient) GetVersion() (string, error) {
-	r1 := h.db.QueryRow(q_GetHanaVersion)
+	ctx, span := h.startSpan(context.Background(), "GetVersion")
+	defer span.End()
+
+	r1 := h.queryRow(ctx, "q_GetHanaVersion", q_GetHanaVersion)
 	var version string
 	err := r1.Scan(&version)
 	if err != nil {
@@ -28,8 +34,11 @@ func (h *HanaUtilClient) GetVersion() (string, error) {
 // if days were set to '1', only the returned slice would only include details
 // of trace files where the modification date is was longer that 24 hours ago.
 func (h *HanaUtilClient) GetTraceFiles(days uint) ([]TraceFile, error) {
+	ctx, span := h.startSpan(context.Background(), "GetTraceFiles")
+	defer span.End()
+
 	TraceFiles := make([]TraceFile, 0)
-	rows, err := h.db.Query(f_GetTraceFiles(days))
+	rows, err := h.query(ctx, "f_GetTraceFiles", f_GetTraceFiles(days))
 
 	if err != nil {
 		/*Promote error*/
@@ -47,14 +56,22 @@ func (h *HanaUtilClient) GetTraceFiles(days uint) ([]TraceFile, error) {
 		}
 		TraceFiles = append(TraceFiles, row)
 	}
+	err = rows.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
 	return TraceFiles, nil
 }
 
 // GetBackupSummary provides a summary of the number of backups along
 // aggregated backup size data found in the backup catalog. The dates of the
-// oldest full and log backups in the catalog are also supplied.
+// oldest and newest backups of each type in the catalog are also supplied.
 func (h *HanaUtilClient) GetBackupSummary() (*BackupSummary, error) {
-	bs, err := h.fetchBackupStats("")
+	ctx, span := h.startSpan(context.Background(), "GetBackupSummary")
+	defer span.End()
+
+	bs, err := h.fetchBackupStats(ctx, BackupFilter{})
 	if err != nil {
 		return nil, err
 	}
@@ -63,12 +80,15 @@ func (h *HanaUtilClient) GetBackupSummary() (*BackupSummary, error) {
 
 // GetFullBackupID returns the latest full backup that is older than the given
 // days in the days argument. The output of this may then be used by
-// GetBackupSummaryBeforeBackupID for information about data that could be
-// removed if a truncation is applied.
+// GetBackupSummaryBeforeBackupID or GetBackupFilesBeforeBackupID for
+// information about data that could be removed if a truncation is applied.
 func (h *HanaUtilClient) GetFullBackupId(days int) (string, error) {
+	ctx, span := h.startSpan(context.Background(), "GetFullBackupId")
+	defer span.End()
+
 	var s string
 
-	r1 := h.db.QueryRow(q_GetLatestFullBackupID(uint(days)))
+	r1 := h.queryRow(ctx, "q_GetLatestFullBackupID", q_GetLatestFullBackupID(uint(days)))
 	err := r1.Scan(&s)
 	if err != nil {
 		//elevate error
@@ -80,145 +100,141 @@ func (h *HanaUtilClient) GetFullBackupId(days int) (string, error) {
 
 // GetBackupSummaryBeforeBackupID provides a summary of the number of backups
 // aggregated backup size data found in the backup catalog that occur before a
-// given backup ID. The dates of the oldest full and log backups in the catalog
-// are also supplied.
+// given backup ID. The dates of the oldest and newest backups of each type
+// that occur before the backup ID are also supplied.
 func (h *HanaUtilClient) GetBackupSummaryBeforeBackupID(b string) (*BackupSummary, error) {
-	bs, err := h.fetchBackupStats(b)
+	ctx, span := h.startSpan(context.Background(), "GetBackupSummaryBeforeBackupID")
+	defer span.End()
+
+	bs, err := h.fetchBackupStats(ctx, BackupFilter{BeforeBackupID: b})
 	if err != nil {
 		return nil, err
 	}
 	return bs, nil
 }
 
-func (h *HanaUtilClient) fetchBackupStats(backupID string) (*BackupSummary, error) {
-	bs := BackupSummary{}
-	var q1, q2, q3 string
-	if backupID == "" {
-		q1 = q_GetBackupCatalogEntryCount
-		q2 = q_GetBackupCount
-		q3 = q_GetBackupSizes
-	} else {
-		q1 = f_GetBackupCatalogEntryCountBeforeID(backupID)
-		q2 = f_GetBackupCountBeforeID(backupID)
-		q3 = f_GetBackupSizesBeforeId(backupID)
-	}
+// GetBackupFilesBeforeBackupID provides the number and size of the backup
+// files found in the backup catalog before a given backup ID. These are the
+// files removed from the catalog when TruncateBackupCatalog is applied with
+// that backup ID.
+// Errors returned are 'InvalidBackupID', when 'b' is not a number, or a DB
+// driver error promoted directly from the DB.
+func (h *HanaUtilClient) GetBackupFilesBeforeBackupID(b string) (*BackupFileStats, error) {
+	ctx, span := h.startSpan(context.Background(), "GetBackupFilesBeforeBackupID")
+	defer span.End()
 
-	r1 := h.db.QueryRow(q1)
-	err := r1.Scan(&bs.BackupCatalogEntries)
+	/*The ID is placed in the query, so it must be a number*/
+	_, err := strconv.ParseUint(b, 10, 64)
 	if err != nil {
-		/*Promote the error*/
-		return nil, err
+		return nil, fmt.Errorf("InvalidBackupID")
 	}
 
-	r2, err := h.db.Query(q2)
+	fs := BackupFileStats{}
+	r1 := h.queryRow(ctx, "f_GetTruncateData", f_GetTruncateData(b))
+	err = r1.Scan(&fs.Files, &fs.Bytes)
 	if err != nil {
-		/*Promote database error*/
+		/*PromoteError*/
 		return nil, err
 	}
-	defer r2.Close()
-
-	for r2.Next() {
-		var tmpCount uint64
-		var tmpType string
-		err = r2.Scan(&tmpCount, &tmpType)
-		if err != nil {
-			/*PromoteError*/
-			return nil, err
-		}
+	return &fs, nil
+}
 
-		switch tmpType {
-		case "complete data backup":
-			bs.FullBackups = tmpCount
-		case "incremental data backup":
-			bs.IncrementalBackups = tmpCount
-		case "differential data backup":
-			bs.DifferentialBackups = tmpCount
-		case "log backup":
-			bs.LogBackups = tmpCount
-		case "log missing":
-			bs.LogMissing = tmpCount
-		case "data snapshot":
-			bs.DataSnapshots = tmpCount
-		default:
-			return nil, fmt.Errorf("UnexpectedBackupType")
-		}
-	}
+// GetBackupSummaryFiltered provides the same summary as GetBackupSummary for
+// the backup catalog entries selected by 'filter'. Every count, size and date
+// of the summary, including the size of the backup catalog, is limited to the
+// selected entries. The size of the catalog is that of the newest successful
+// catalog backup selected, or 0 if there is none.
+// Errors returned are 'InvalidBackupID', when BeforeBackupID is not a number,
+// 'UnexpectedBackupType', in strict mode, or a DB driver error promoted
+// directly from the DB.
+func (h *HanaUtilClient) GetBackupSummaryFiltered(filter BackupFilter) (*BackupSummary, error) {
+	ctx, span := h.startSpan(context.Background(), "GetBackupSummaryFiltered")
+	defer span.End()
 
-	r3, err := h.db.Query(q3)
+	bs, err := h.fetchBackupStats(ctx, filter)
 	if err != nil {
-		/*Promote database error*/
 		return nil, err
 	}
-	defer r3.Close()
+	return bs, nil
+}
 
-	for r3.Next() {
-		var tmpType string
-		var tmpCount uint64
-		err = r3.Scan(&tmpType, &tmpCount)
+// fetchBackupStats reads the summary of the entries selected by 'filter' in a
+// single statement, so that it costs one round trip to the database
+func (h *HanaUtilClient) fetchBackupStats(ctx context.Context, filter BackupFilter) (*BackupSummary, error) {
+	types := make(map[string]BackupTypeStats)
+	if filter.BeforeBackupID != "" {
+		/*The ID is placed in the query, so it must be a number*/
+		_, err := strconv.ParseUint(filter.BeforeBackupID, 10, 64)
 		if err != nil {
-			/*PromoteError*/
-			return nil, err
-		}
-		switch tmpType {
-		case "complete data backup":
-			bs.SizeOfFullBackupsBytes = tmpCount
-		case "incremental data backup":
-			bs.SizeOfIncrementalBackups = tmpCount
-		case "differential data backup":
-			bs.SizeOfDifferentialBackups = tmpCount
-		case "log backup":
-			bs.SizeOfLogBackupBytes = tmpCount
-		case "log missing":
-			bs.SizeOfLogMissing = tmpCount
-		case "data snapshot":
-			bs.SizeOfDataSnapshots = tmpCount
-		default:
-			return nil, fmt.Errorf("UnexpectedBackupType")
+			return nil, fmt.Errorf("InvalidBackupID")
 		}
 	}
 
-	r4, err := h.db.Query(q_GetOldestBackups)
+	rows, err := h.query(ctx, "f_GetBackupSummary", f_GetBackupSummary(filter))
 	if err != nil {
 		/*Promote database error*/
 		return nil, err
 	}
-	defer r4.Close()
+	defer rows.Close()
 
-	for r4.Next() {
-		var tmpType string
-		var tmpDate time.Time
-		err = r4.Scan(&tmpType, &tmpDate)
+	/*Every row carries the catalog size and time, an empty catalog one row*/
+	found := false
+	var catalogBytes uint64
+	var now time.Time
+	for rows.Next() {
+		var tmpType sql.NullString
+		var oldest, newest sql.NullTime
+		var t BackupTypeStats
+		err = rows.Scan(&tmpType, &t.Count, &t.SizeBytes, &oldest, &newest, &catalogBytes, &now)
 		if err != nil {
-			/*Promote error*/
+			/*PromoteError*/
 			return nil, err
 		}
-		switch tmpType {
-		case "complete data backup":
-			bs.OldestFullBackupDate = tmpDate
-		case "log backup":
-			bs.OldestLogBackupDate = tmpDate
-		default:
+		found = true
+		if !tmpType.Valid {
+			continue
+		}
+		if h.strictBackupTypes && !knownEntryType(tmpType.String) {
 			return nil, fmt.Errorf("UnexpectedBackupType")
 		}
+		t.Dates.Oldest, t.Dates.Newest = oldest.Time, newest.Time
+		types[tmpType.String] = t
 	}
-
-	var backupCatalogSize uint64
-	r5 := h.db.QueryRow(q_GetBackupCatalogSize)
-	err = r5.Scan(&backupCatalogSize)
+	err = rows.Err()
 	if err != nil {
 		/*PromoteError*/
 		return nil, err
 	}
-	bs.SizeOfBackupCatalog = backupCatalogSize
+	if !found {
+		return nil, sql.ErrNoRows
+	}
 
-	r6 := h.db.QueryRow(q_GetDbCurrentTime)
-	err = r6.Scan(&bs.CurrentDbTime)
+	return NewBackupSummary(types, catalogBytes, now), nil
+}
+
+// GetLatestBackups provides the start times of the newest successful full and
+// log backups along with the current database time, all in UTC, so that the
+// age of the latest backups can be monitored.
+func (h *HanaUtilClient) GetLatestBackups() (*LatestBackups, error) {
+	ctx, span := h.startSpan(context.Background(), "GetLatestBackups")
+	defer span.End()
+
+	lb := LatestBackups{}
+	var full, log sql.NullTime
+	r1 := h.queryRow(ctx, "q_GetLatestBackups", q_GetLatestBackups)
+	err := r1.Scan(&full, &log, &lb.CurrentDbTime)
 	if err != nil {
-		/*Promote the error*/
+		/*PromoteError*/
 		return nil, err
 	}
-
-	return &bs, nil
+	/*An empty catalog has no latest backups*/
+	if full.Valid {
+		lb.FullBackup = full.Time
+	}
+	if log.Valid {
+		lb.LogBackup = log.Time
+	}
+	return &lb, nil
 }
 
 // GetStatServerAlerts is a function that reports the number of historic alerts
@@ -229,8 +245,11 @@ func (h *HanaUtilClient) fetchBackupStats(backupID string) (*BackupSummary, erro
 // Errors returned are either 'UnexpectedDbReturn', when the query produces an
 // unexpected value or a DB driver error promoted directly from the DB.
 func (h *HanaUtilClient) GetStatServerAlerts(days uint) (uint, error) {
+	ctx, span := h.startSpan(context.Background(), "GetStatServerAlerts")
+	defer span.End()
+
 	var alerts uint
-	r1 := h.db.QueryRow(f_GetStatServerAlerts(days))
+	r1 := h.queryRow(ctx, "f_GetStatServerAlerts", f_GetStatServerAlerts(days))
 	err := r1.Scan(&alerts)
 	if err != nil {
 		/*PromoteError*/
@@ -242,12 +261,16 @@ func (h *HanaUtilClient) GetStatServerAlerts(days uint) (uint, error) {
 // GetLogSegmentStats provides information about the free and non-free
 // log segments in the HANA log volume
 func (h *HanaUtilClient) GetLogSegmentStats() (*LogSegmentsStats, error) {
+	ctx, span := h.startSpan(context.Background(), "GetLogSegmentStats")
+	defer span.End()
+
 	ls := LogSegmentsStats{}
-	r1, err := h.db.Query(q_GetLogSegmentStats)
+	r1, err := h.query(ctx, "q_GetLogSegmentStats", q_GetLogSegmentStats)
 	if err != nil {
 		/*PromoteError*/
 		return nil, err
 	}
+	defer r1.Close()
 
 	for r1.Next() {
 		var tmpState string
@@ -269,6 +292,479 @@ func (h *HanaUtilClient) GetLogSegmentStats() (*LogSegmentsStats, error) {
 		}
 
 	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
 
 	return &ls, nil
 }
+
+// fetchFreeLogSegments returns the free log segments held by each service along
+// with the database time at which they were measured
+func (h *HanaUtilClient) fetchFreeLogSegments(ctx context.Context) ([]ServiceLogSegments, time.Time, error) {
+	var dbTime time.Time
+	segs := make([]ServiceLogSegments, 0)
+	r1, err := h.query(ctx, "q_GetFreeLogSegmentsByService", q_GetFreeLogSegmentsByService)
+	if err != nil {
+		/*PromoteError*/
+		return nil, dbTime, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := ServiceLogSegments{}
+		err = r1.Scan(&row.Hostname, &row.Port, &row.ServiceName, &row.FreeSegments, &row.FreeSegmentBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, dbTime, err
+		}
+		segs = append(segs, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, dbTime, err
+	}
+
+	r2 := h.queryRow(ctx, "q_GetDbCurrentTime", q_GetDbCurrentTime)
+	err = r2.Scan(&dbTime)
+	if err != nil {
+		/*PromoteError*/
+		return nil, dbTime, err
+	}
+
+	return segs, dbTime, nil
+}
+
+// GetDiskUsage provides the total, used and free bytes of each of the disks
+// used by HANA for data, log, trace and backup on each host. This can be used
+// to decide when housekeeping should be triggered.
+func (h *HanaUtilClient) GetDiskUsage() ([]DiskUsage, error) {
+	ctx, span := h.startSpan(context.Background(), "GetDiskUsage")
+	defer span.End()
+
+	disks := make([]DiskUsage, 0)
+	r1, err := h.query(ctx, "q_GetDiskUsage", q_GetDiskUsage)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := DiskUsage{}
+		err = r1.Scan(&row.Hostname, &row.Path, &row.UsageType, &row.FilesystemType,
+			&row.TotalBytes, &row.UsedBytes, &row.HanaUsedBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		/*Mitigation around potentially going less than zero on uint vars*/
+		if row.UsedBytes < row.TotalBytes {
+			row.FreeBytes = row.TotalBytes - row.UsedBytes
+		}
+		disks = append(disks, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return disks, nil
+}
+
+// GetVolumeUsage provides the total, used and free bytes of each of the data
+// and log volume files of each service.
+func (h *HanaUtilClient) GetVolumeUsage() ([]VolumeUsage, error) {
+	ctx, span := h.startSpan(context.Background(), "GetVolumeUsage")
+	defer span.End()
+
+	volumes := make([]VolumeUsage, 0)
+	r1, err := h.query(ctx, "q_GetVolumeUsage", q_GetVolumeUsage)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := VolumeUsage{}
+		err = r1.Scan(&row.Hostname, &row.Port, &row.VolumeID, &row.FileType,
+			&row.FileName, &row.TotalBytes, &row.UsedBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		/*Mitigation around potentially going less than zero on uint vars*/
+		if row.UsedBytes < row.TotalBytes {
+			row.FreeBytes = row.TotalBytes - row.UsedBytes
+		}
+		volumes = append(volumes, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return volumes, nil
+}
+
+// GetMemoryUsage provides the used, peak and allocation limit memory of each
+// host and service, along with the effective allocation limit of each service.
+// The 'topN' argument sets how many of the largest heap allocators are
+// returned.
+func (h *HanaUtilClient) GetMemoryUsage(topN uint) (*MemoryUsage, error) {
+	ctx, span := h.startSpan(context.Background(), "GetMemoryUsage")
+	defer span.End()
+
+	mu := MemoryUsage{
+		Hosts:             make([]HostMemory, 0),
+		Services:          make([]ServiceMemory, 0),
+		TopHeapAllocators: make([]HeapAllocator, 0),
+	}
+
+	r1, err := h.query(ctx, "q_GetHostMemory", q_GetHostMemory)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := HostMemory{}
+		err = r1.Scan(&row.Hostname, &row.UsedBytes, &row.PeakUsedBytes,
+			&row.AllocatedBytes, &row.AllocationLimitBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		mu.Hosts = append(mu.Hosts, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	r2, err := h.query(ctx, "q_GetServiceMemory", q_GetServiceMemory)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r2.Close()
+
+	for r2.Next() {
+		row := ServiceMemory{}
+		err = r2.Scan(&row.Hostname, &row.Port, &row.ServiceName, &row.UsedBytes,
+			&row.PeakUsedBytes, &row.AllocationLimitBytes, &row.EffectiveAllocationLimitBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		mu.Services = append(mu.Services, row)
+	}
+	err = r2.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	if topN == 0 {
+		return &mu, nil
+	}
+
+	r3, err := h.query(ctx, "f_GetTopHeapAllocators", f_GetTopHeapAllocators(topN))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r3.Close()
+
+	for r3.Next() {
+		row := HeapAllocator{}
+		err = r3.Scan(&row.Hostname, &row.Port, &row.Category, &row.InUseBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		mu.TopHeapAllocators = append(mu.TopHeapAllocators, row)
+	}
+	err = r3.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	return &mu, nil
+}
+
+// GetTableSizes returns the largest column and row store tables ordered by
+// memory size. The 'filter' argument restricts the schema, store type and
+// number of tables returned. If the StoreType of the filter is not "COLUMN",
+// "ROW" or empty, the error 'InvalidStoreType' is returned.
+func (h *HanaUtilClient) GetTableSizes(filter TableSizeFilter) ([]TableSize, error) {
+	ctx, span := h.startSpan(context.Background(), "GetTableSizes")
+	defer span.End()
+
+	if filter.StoreType != "" && filter.StoreType != "COLUMN" && filter.StoreType != "ROW" {
+		return nil, fmt.Errorf("InvalidStoreType")
+	}
+
+	tables := make([]TableSize, 0)
+	r1, err := h.query(ctx, "f_GetTableSizes", f_GetTableSizes(filter.SchemaName, filter.StoreType, filter.Limit))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := TableSize{}
+		err = r1.Scan(&row.SchemaName, &row.TableName, &row.StoreType, &row.MemorySizeBytes,
+			&row.DiskSizeBytes, &row.RecordCount, &row.DeltaSizeBytes, &row.Partitions)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		tables = append(tables, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return tables, nil
+}
+
+// GetTableUnloads returns the column store unloads that have occurred within
+// the number of days given by the 'days' argument, newest first. Frequent
+// unloads due to low memory are a sign that the database is outgrowing its
+// memory.
+func (h *HanaUtilClient) GetTableUnloads(days uint) ([]TableUnload, error) {
+	ctx, span := h.startSpan(context.Background(), "GetTableUnloads")
+	defer span.End()
+
+	unloads := make([]TableUnload, 0)
+	r1, err := h.query(ctx, "f_GetTableUnloads", f_GetTableUnloads(days))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := TableUnload{}
+		err = r1.Scan(&row.UnloadTime, &row.Hostname, &row.Port, &row.SchemaName,
+			&row.TableName, &row.ColumnName, &row.Reason)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		unloads = append(unloads, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return unloads, nil
+}
+
+// GetDeltaMergeCandidates returns the column store tables where the delta
+// storage meets the given 'thresholds', largest delta first. Tables with large
+// deltas consume additional memory and slow down reads, these can be merged
+// with MergeDelta.
+func (h *HanaUtilClient) GetDeltaMergeCandidates(thresholds DeltaMergeThresholds) ([]DeltaMergeCandidate, error) {
+	ctx, span := h.startSpan(context.Background(), "GetDeltaMergeCandidates")
+	defer span.End()
+
+	candidates := make([]DeltaMergeCandidate, 0)
+	r1, err := h.query(ctx, "f_GetDeltaMergeCandidates", f_GetDeltaMergeCandidates(thresholds.MinDeltaBytes, thresholds.MinDeltaRecords))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := DeltaMergeCandidate{}
+		var lastMerge sql.NullTime
+		err = r1.Scan(&row.SchemaName, &row.TableName, &row.DeltaSizeBytes, &row.DeltaRecordCount,
+			&row.MainSizeBytes, &lastMerge, &row.FailedMerges)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		/*Tables that have never been merged have no merge time*/
+		if lastMerge.Valid {
+			row.LastMergeTime = lastMerge.Time
+		}
+		candidates = append(candidates, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// ListStatServerAlerts returns the alerts stored in the
+// _SYS_STATISTICS.STATISTICS_ALERTS_BASE table that match the given 'filter',
+// newest first. Unlike GetStatServerAlerts, which only counts historic alerts,
+// this allows current alerts to be inspected and acted upon.
+func (h *HanaUtilClient) ListStatServerAlerts(filter StatServerAlertFilter) ([]StatServerAlert, error) {
+	ctx, span := h.startSpan(context.Background(), "ListStatServerAlerts")
+	defer span.End()
+
+	alerts := make([]StatServerAlert, 0)
+	r1, err := h.query(ctx, "f_ListStatServerAlerts", f_ListStatServerAlerts(filter.MinRating, filter.From, filter.To, filter.Limit))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := StatServerAlert{}
+		err = r1.Scan(&row.AlertID, &row.AlertName, &row.Rating, &row.Hostname, &row.Port,
+			&row.Timestamp, &row.Details, &row.UserAction)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		alerts = append(alerts, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// SummariseStatServerAlerts returns a summary of the alerts stored in the
+// _SYS_STATISTICS.STATISTICS_ALERTS_BASE table that match the given 'filter',
+// grouped by alert ID. The Limit of the filter is ignored.
+func (h *HanaUtilClient) SummariseStatServerAlerts(filter StatServerAlertFilter) ([]StatServerAlertSummary, error) {
+	ctx, span := h.startSpan(context.Background(), "SummariseStatServerAlerts")
+	defer span.End()
+
+	summary := make([]StatServerAlertSummary, 0)
+	r1, err := h.query(ctx, "f_GetStatServerAlertSummary", f_GetStatServerAlertSummary(filter.MinRating, filter.From, filter.To))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := StatServerAlertSummary{}
+		err = r1.Scan(&row.AlertID, &row.AlertName, &row.Count, &row.MaxRating,
+			&row.FirstTimestamp, &row.LastTimestamp)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		summary = append(summary, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return summary, nil
+}
+
+// GetStatisticsHistorySizes returns the number of rows and size of each of the
+// statistics server collector history tables (HOST_* and GLOBAL_*) in the
+// _SYS_STATISTICS schema, largest first. SAP HANA minichecks will flag
+// oversized history tables, these can be reduced with PurgeStatisticsHistory.
+func (h *HanaUtilClient) GetStatisticsHistorySizes() ([]StatisticsHistoryTable, error) {
+	ctx, span := h.startSpan(context.Background(), "GetStatisticsHistorySizes")
+	defer span.End()
+
+	tables := make([]StatisticsHistoryTable, 0)
+	r1, err := h.query(ctx, "q_GetStatisticsHistorySizes", q_GetStatisticsHistorySizes)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	for r1.Next() {
+		row := StatisticsHistoryTable{}
+		err = r1.Scan(&row.TableName, &row.RecordCount, &row.TableSizeBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		tables = append(tables, row)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return tables, nil
+}
+
+// GetAuditLogStats provides the number of entries, the oldest and newest entry
+// and the size of the audit log table. When the audit trail is written to the
+// CSTABLE target the table grows without bound, it can be reduced with
+// TruncateAuditLog.
+func (h *HanaUtilClient) GetAuditLogStats() (*AuditLogStats, error) {
+	ctx, span := h.startSpan(context.Background(), "GetAuditLogStats")
+	defer span.End()
+
+	as := AuditLogStats{}
+	var oldest, newest sql.NullTime
+	r1 := h.queryRow(ctx, "q_GetAuditLogStats", q_GetAuditLogStats)
+	err := r1.Scan(&as.Entries, &oldest, &newest, &as.TableSizeBytes)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	/*An empty audit log has no oldest or newest entry*/
+	if oldest.Valid {
+		as.OldestEntry = oldest.Time
+	}
+	if newest.Valid {
+		as.NewestEntry = newest.Time
+	}
+	return &as, nil
+}
+
+// GetSqlPlanCacheStats provides the number of plans held in the SQL plan cache,
+// the memory they use and the capacity of the cache.
+func (h *HanaUtilClient) GetSqlPlanCacheStats() (*SqlPlanCacheStats, error) {
+	ctx, span := h.startSpan(context.Background(), "GetSqlPlanCacheStats")
+	defer span.End()
+
+	ps := SqlPlanCacheStats{}
+	r1 := h.queryRow(ctx, "q_GetSqlPlanCacheStats", q_GetSqlPlanCacheStats)
+	err := r1.Scan(&ps.CachedPlans, &ps.CachedPlanBytes, &ps.CapacityBytes)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return &ps, nil
+}
+
+// GetExpensiveStatementsStats provides the number of entries held by the
+// expensive statements trace and the memory it uses.
+func (h *HanaUtilClient) GetExpensiveStatementsStats() (*ExpensiveStatementsStats, error) {
+	ctx, span := h.startSpan(context.Background(), "GetExpensiveStatementsStats")
+	defer span.End()
+
+	es := ExpensiveStatementsStats{}
+	r1 := h.queryRow(ctx, "q_GetExpensiveStatementsStats", q_GetExpensiveStatementsStats)
+	err := r1.Scan(&es.Entries, &es.MemoryBytes)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	return &es, nil
+}
@@ -1,9 +1,12 @@
 package hanautil
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -137,6 +140,9 @@ func Test_hanaUtilClient_GetTraceFiles(t *testing.T) {
 	}
 }
 
+// backupSummaryColumns are the columns returned by f_GetBackupSummary
+var backupSummaryColumns = []string{"ENTRY_TYPE_NAME", "ENTRIES", "BYTES", "OLDEST", "NEWEST", "CATALOG_SIZE", "CURRENT_TIME"}
+
 func Test_hanaUtilClient_GetBackupSummary(t *testing.T) {
 	/*Test Setup*/
 	/*Mock DB*/
@@ -160,216 +166,85 @@ func Test_hanaUtilClient_GetBackupSummary(t *testing.T) {
 		wantErr bool
 	}{
 		{"Good01", fields{db1, ""}, &BackupSummary{
-			100, 10, 90, 30, 0, 0, 0, 1024000, 512000, 0, 0, 0, 0, 10240, genTime, genTime, genTime}, false},
+			130, 10, 90, 30, 0, 0, 0, 1024000, 512000, 0, 0, 0, 0, 10240, genTime, genTime, genTime,
+			BackupDates{genTime, genTime, 0, 0}, BackupDates{genTime, genTime, 0, 0}, BackupDates{}, BackupDates{}, BackupDates{},
+			map[string]BackupTypeStats{
+				EntryTypeFullBackup:        {10, 1024000, BackupDates{genTime, genTime, 0, 0}},
+				EntryTypeLogBackup:         {90, 512000, BackupDates{genTime, genTime, 0, 0}},
+				EntryTypeIncrementalBackup: {30, 0, BackupDates{}},
+			}}, false},
 		{"Good02", fields{db1, ""}, &BackupSummary{
-			60, 10, 10, 10, 10, 10, 10, 1024, 1024, 1024, 1024, 1024, 1024, 10240, genTime, genTime, genTime}, false},
-		{"BackupCatalogSizeDbError", fields{db1, ""}, nil, true},
-		{"OldestBackupUnexpectedResult", fields{db1, ""}, nil, true},
-		{"OldestBackupScanError", fields{db1, ""}, nil, true},
-		{"OldestBackupDbError", fields{db1, ""}, nil, true},
-		{"BackupSizeUnexpectedResult", fields{db1, ""}, nil, true},
-		{"BackupSizeDbError", fields{db1, ""}, nil, true},
-		{"BackupSizeScanError", fields{db1, ""}, nil, true},
-		{"GetBackupCountUnexpectedResult", fields{db1, ""}, nil, true},
-		{"BackupCountScanError", fields{db1, ""}, nil, true},
-		{"BackupCountDbError", fields{db1, ""}, nil, true},
-		{"BackupCatalogEntryCountScanError", fields{db1, ""}, nil, true},
-		{"BackupCatalogEntryCountDbError", fields{db1, ""}, nil, true},
-		{"CurrentTimeError", fields{db1, ""}, nil, true},
+			60, 10, 10, 10, 10, 10, 10, 1024, 1024, 1024, 1024, 1024, 1024, 10240, genTime, genTime, genTime,
+			BackupDates{genTime, genTime, 0, 0}, BackupDates{genTime, genTime, 0, 0}, BackupDates{}, BackupDates{}, BackupDates{},
+			map[string]BackupTypeStats{
+				EntryTypeFullBackup:         {10, 1024, BackupDates{genTime, genTime, 0, 0}},
+				EntryTypeIncrementalBackup:  {10, 1024, BackupDates{}},
+				EntryTypeDifferentialBackup: {10, 1024, BackupDates{}},
+				EntryTypeLogBackup:          {10, 1024, BackupDates{genTime, genTime, 0, 0}},
+				EntryTypeLogMissing:         {10, 1024, BackupDates{}},
+				EntryTypeDataSnapshot:       {10, 1024, BackupDates{}},
+			}}, false},
+		{"GoodUnknownType", fields{db1, ""}, &BackupSummary{
+			30, 10, 10, 0, 0, 0, 0, 1024, 512, 0, 0, 0, 0, 10240, genTime, genTime, genTime,
+			BackupDates{genTime, genTime, 0, 0}, BackupDates{genTime, genTime, 0, 0}, BackupDates{}, BackupDates{}, BackupDates{},
+			map[string]BackupTypeStats{
+				EntryTypeFullBackup: {10, 1024, BackupDates{genTime, genTime, 0, 0}},
+				EntryTypeLogBackup:  {10, 512, BackupDates{genTime, genTime, 0, 0}},
+				"tape backup":       {10, 2048, BackupDates{genTime.Add(-time.Hour), genTime.Add(-time.Hour), 3600, 3600}},
+			}}, false},
+		{"GoodEmptyCatalog", fields{db1, ""}, &BackupSummary{CurrentDbTime: genTime, Types: map[string]BackupTypeStats{}}, false},
+		{"BackupTypeUnexpectedResult", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+		{"RowError", fields{db1, ""}, nil, true},
+		{"NoRows", fields{db1, ""}, nil, true},
+		{"DbError", fields{db1, ""}, nil, true},
 	}
 	for _, tt := range tests {
 		/*Set up per case mocking*/
 		switch tt.name {
 		case "Good01":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows2.AddRow(30, "incremental data backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("complete data backup", genTime)
-			rows4.AddRow("log backup", genTime)
-			rows5 := mock.NewRows([]string{"BF.BACKUP_SIZE"}).AddRow(10240)
-			rows6 := mock.NewRows([]string{"CURRENT_TIME}"}).AddRow(genTime)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-			mock.ExpectQuery(q_GetBackupCatalogSize).WillReturnRows(rows5)
-			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(rows6)
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 10, 1024000, genTime, genTime, 10240, genTime)
+			rows.AddRow("incremental data backup", 30, 0, nil, nil, 10240, genTime)
+			rows.AddRow("log backup", 90, 512000, genTime, genTime, 10240, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
 		case "Good02":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(60)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(10, "incremental data backup")
-			rows2.AddRow(10, "differential data backup")
-			rows2.AddRow(10, "log backup")
-			rows2.AddRow(10, "log missing")
-			rows2.AddRow(10, "data snapshot")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024)
-			rows3.AddRow("incremental data backup", 1024)
-			rows3.AddRow("differential data backup", 1024)
-			rows3.AddRow("log backup", 1024)
-			rows3.AddRow("log missing", 1024)
-			rows3.AddRow("data snapshot", 1024)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("complete data backup", genTime)
-			rows4.AddRow("log backup", genTime)
-			rows5 := mock.NewRows([]string{"BF.BACKUP_SIZE"}).AddRow(10240)
-			rows6 := mock.NewRows([]string{"CURRENT_TIME}"}).AddRow(genTime)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-			mock.ExpectQuery(q_GetBackupCatalogSize).WillReturnRows(rows5)
-			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(rows6)
-		case "OldestBackupUnexpectedResult":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("not an expected field", genTime)
-			rows4.AddRow("log backup", genTime)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-		case "OldestBackupScanError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("not an expected field", "a string")
-			rows4.AddRow("log backup", 0.1)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-		case "OldestBackupDbError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnError(fmt.Errorf("DbError"))
-		case "BackupCatalogSizeDbError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("complete data backup", genTime)
-			rows4.AddRow("log backup", genTime)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-			mock.ExpectQuery(q_GetBackupCatalogSize).WillReturnError(fmt.Errorf("DbError"))
-		case "BackupSizeUnexpectedResult":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("unexpected result", 512000)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-		case "BackupSizeScanError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("log backup", "-5")
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-		case "BackupSizeDbError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnError(fmt.Errorf("DbError"))
-		case "GetBackupCountUnexpectedResult":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "super unexpected log backup")
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-		case "BackupCountScanError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow("-10", "complete data backup")
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-		case "BackupCountDbError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnError(fmt.Errorf("DbError"))
-		case "BackupCatalogEntryCountScanError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow("-1")
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-		case "BackupCatalogEntryCountDbError":
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnError(fmt.Errorf("DbError"))
-		case "CurrentTimeError":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("complete data backup", genTime)
-			rows4.AddRow("log backup", genTime)
-			rows5 := mock.NewRows([]string{"BF.BACKUP_SIZE"}).AddRow(10240)
-			rows6 := mock.NewRows([]string{"CURRENT_TIME}"})
-			rows6.AddRow(genTime)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(q_GetBackupCatalogEntryCount).WillReturnRows(rows1)
-			mock.ExpectQuery(q_GetBackupCount).WillReturnRows(rows2)
-			mock.ExpectQuery(q_GetBackupSizes).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-			mock.ExpectQuery(q_GetBackupCatalogSize).WillReturnRows(rows5)
-			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnError(fmt.Errorf("DbError"))
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 10, 1024, genTime, genTime, 10240, genTime)
+			rows.AddRow("data snapshot", 10, 1024, nil, nil, 10240, genTime)
+			rows.AddRow("differential data backup", 10, 1024, nil, nil, 10240, genTime)
+			rows.AddRow("incremental data backup", 10, 1024, nil, nil, 10240, genTime)
+			rows.AddRow("log backup", 10, 1024, genTime, genTime, 10240, genTime)
+			rows.AddRow("log missing", 10, 1024, nil, nil, 10240, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
+		case "GoodUnknownType":
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 10, 1024, genTime, genTime, 10240, genTime)
+			rows.AddRow("log backup", 10, 512, genTime, genTime, 10240, genTime)
+			rows.AddRow("tape backup", 10, 2048, genTime.Add(-time.Hour), genTime.Add(-time.Hour), 10240, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
+		case "GoodEmptyCatalog":
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow(nil, 0, 0, nil, nil, 0, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
+		case "BackupTypeUnexpectedResult":
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 10, 1024, genTime, genTime, 10240, genTime)
+			rows.AddRow("super unexpected log backup", 90, 512000, genTime, genTime, 10240, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
+		case "ScanError":
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", "-10", 1024, genTime, genTime, 10240, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
+		case "RowError":
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 10, 1024, genTime, genTime, 10240, genTime)
+			rows.RowError(0, fmt.Errorf("RowError"))
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnRows(mock.NewRows(backupSummaryColumns))
+		case "DbError":
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillReturnError(fmt.Errorf("DbError"))
 		default:
 			fmt.Printf("No test case matched for %s\n", tt.name)
 			t.Errorf("No test case matched")
@@ -379,6 +254,8 @@ func Test_hanaUtilClient_GetBackupSummary(t *testing.T) {
 				db:  tt.fields.db,
 				dsn: tt.fields.dsn,
 			}
+			/*Unknown types are only errors in strict mode*/
+			h.SetStrictBackupTypes(strings.HasSuffix(tt.name, "UnexpectedResult"))
 			got, err := h.GetBackupSummary()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("hanaUtilClient.GetBackupSummary() error = %v, wantErr %v", err, tt.wantErr)
@@ -389,6 +266,9 @@ func Test_hanaUtilClient_GetBackupSummary(t *testing.T) {
 			}
 		})
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_hanaUtilClient_GetStatServerAlerts(t *testing.T) {
@@ -534,34 +414,24 @@ func TestHanaUtilClient_GetBackupSummaryBeforeBackupID(t *testing.T) {
 		wantErr bool
 	}{
 		{"Good01", fields{db1, ""}, args{"123"}, &BackupSummary{
-			100, 10, 90, 0, 0, 0, 0, 1024000, 512000, 0, 0, 0, 0, 10240, genTime, genTime, genTime}, false},
+			100, 10, 90, 0, 0, 0, 0, 1024000, 512000, 0, 0, 0, 0, 10240, genTime, genTime, genTime,
+			BackupDates{genTime, genTime, 0, 0}, BackupDates{genTime, genTime, 0, 0}, BackupDates{}, BackupDates{}, BackupDates{},
+			map[string]BackupTypeStats{
+				EntryTypeFullBackup: {10, 1024000, BackupDates{genTime, genTime, 0, 0}},
+				EntryTypeLogBackup:  {90, 512000, BackupDates{genTime, genTime, 0, 0}},
+			}}, false},
 		{"Bad", fields{db1, ""}, args{"123"}, nil, true},
 	}
 	for _, tt := range tests {
 		/*per case mocking*/
 		switch {
 		case tt.name == "Good01":
-			rows1 := mock.NewRows([]string{"COUNT"}).AddRow(100)
-			rows2 := mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"})
-			rows2.AddRow(10, "complete data backup")
-			rows2.AddRow(90, "log backup")
-			rows3 := mock.NewRows([]string{"TYPES", "BYTES"})
-			rows3.AddRow("complete data backup", 1024000)
-			rows3.AddRow("log backup", 512000)
-			rows4 := mock.NewRows([]string{"ENTRY_TYPE_NAME", "UTC_START_NAME"})
-			rows4.AddRow("complete data backup", genTime)
-			rows4.AddRow("log backup", genTime)
-			rows5 := mock.NewRows([]string{"BF.BACKUP_SIZE"}).AddRow(10240)
-			rows6 := mock.NewRows([]string{"CURRENT_TIME}"}).AddRow(genTime)
-			/*Now do the sequencing*/
-			mock.ExpectQuery(f_GetBackupCatalogEntryCountBeforeID(tt.args.b)).WillReturnRows(rows1)
-			mock.ExpectQuery(f_GetBackupCountBeforeID(tt.args.b)).WillReturnRows(rows2)
-			mock.ExpectQuery(f_GetBackupSizesBeforeId(tt.args.b)).WillReturnRows(rows3)
-			mock.ExpectQuery(q_GetOldestBackups).WillReturnRows(rows4)
-			mock.ExpectQuery(q_GetBackupCatalogSize).WillReturnRows(rows5)
-			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(rows6)
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 10, 1024000, genTime, genTime, 10240, genTime)
+			rows.AddRow("log backup", 90, 512000, genTime, genTime, 10240, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{BeforeBackupID: tt.args.b})).WillReturnRows(rows)
 		case tt.name == "Bad":
-			mock.ExpectQuery(f_GetBackupCatalogEntryCountBeforeID(tt.args.b)).WillReturnError(fmt.Errorf("DB error"))
+			mock.ExpectQuery(f_GetBackupSummary(BackupFilter{BeforeBackupID: tt.args.b})).WillReturnError(fmt.Errorf("DB error"))
 		default:
 			fmt.Printf("No test case matched for %s\n", tt.name)
 			t.Errorf("No test case matched")
@@ -584,6 +454,63 @@ func TestHanaUtilClient_GetBackupSummaryBeforeBackupID(t *testing.T) {
 	}
 }
 
+func TestHanaUtilClient_GetBackupFilesBeforeBackupID(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		b string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *BackupFileStats
+		wantErr bool
+	}{
+		{"Good01", fields{db1, ""}, args{"123"}, &BackupFileStats{100, 1024000}, false},
+		{"InvalidBackupID", fields{db1, ""}, args{"1'; --"}, nil, true},
+		{"DbError", fields{db1, ""}, args{"123"}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good01":
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow(100, 1024000))
+		case "InvalidBackupID":
+			/*No queries expected*/
+		case "DbError":
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetBackupFilesBeforeBackupID(tt.args.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetBackupFilesBeforeBackupID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetBackupFilesBeforeBackupID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestHanaUtilClient_GetFullBackupId(t *testing.T) {
 	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
 	if err != nil {
@@ -637,3 +564,1197 @@ func TestHanaUtilClient_GetFullBackupId(t *testing.T) {
 		})
 	}
 }
+
+func TestHanaUtilClient_GetDiskUsage(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"HOST", "PATH", "USAGE_TYPE", "FILESYSTEM_TYPE", "TOTAL_SIZE", "USED_SIZE", "HANA_USED_SIZE"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []DiskUsage
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, []DiskUsage{
+			{"hana01", "/hana/data/HDB/", "DATA", "xfs", 1000, 600, 400, 500},
+			{"hana01", "/hana/log/HDB/", "LOG", "xfs", 500, 100, 400, 0}}, false},
+		{"UsedExceedsTotal", fields{db1, ""}, []DiskUsage{
+			{"hana01", "/usr/sap/HDB/HDB00/hana01/trace/", "TRACE", "nfs", 100, 150, 0, 10}}, false},
+		{"NoRows", fields{db1, ""}, []DiskUsage{}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow("hana01", "/hana/data/HDB/", "DATA", "xfs", 1000, 600, 500)
+			rows.AddRow("hana01", "/hana/log/HDB/", "LOG", "xfs", 500, 100, 0)
+			mock.ExpectQuery(q_GetDiskUsage).WillReturnRows(rows)
+		case "UsedExceedsTotal":
+			rows := mock.NewRows(cols)
+			rows.AddRow("hana01", "/usr/sap/HDB/HDB00/hana01/trace/", "TRACE", "nfs", 100, 150, 10)
+			mock.ExpectQuery(q_GetDiskUsage).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(q_GetDiskUsage).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(q_GetDiskUsage).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow("hana01", "/hana/data/HDB/", "DATA", "xfs", "-1000", 600, 500)
+			mock.ExpectQuery(q_GetDiskUsage).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetDiskUsage()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetDiskUsage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetDiskUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetVolumeUsage(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"HOST", "PORT", "VOLUME_ID", "FILE_TYPE", "FILE_NAME", "TOTAL_SIZE", "USED_SIZE"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []VolumeUsage
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, []VolumeUsage{
+			{"hana01", 30003, 3, "DATA", "/hana/data/HDB/mnt00001/hdb00003.00003/datavolume_0000.dat", 4096, 1024, 3072},
+			{"hana01", 30003, 3, "LOG", "/hana/log/HDB/mnt00001/hdb00003.00003/logsegment_000_00000000.dat", 1024, 1024, 0}}, false},
+		{"NoRows", fields{db1, ""}, []VolumeUsage{}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow("hana01", 30003, 3, "DATA", "/hana/data/HDB/mnt00001/hdb00003.00003/datavolume_0000.dat", 4096, 1024)
+			rows.AddRow("hana01", 30003, 3, "LOG", "/hana/log/HDB/mnt00001/hdb00003.00003/logsegment_000_00000000.dat", 1024, 1024)
+			mock.ExpectQuery(q_GetVolumeUsage).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(q_GetVolumeUsage).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(q_GetVolumeUsage).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow("hana01", "not a port", 3, "DATA", "datavolume_0000.dat", 4096, 1024)
+			mock.ExpectQuery(q_GetVolumeUsage).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetVolumeUsage()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetVolumeUsage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetVolumeUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetMemoryUsage(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	hostCols := []string{"HOST", "INSTANCE_TOTAL_MEMORY_USED_SIZE", "INSTANCE_TOTAL_MEMORY_PEAK_USED_SIZE",
+		"INSTANCE_TOTAL_MEMORY_ALLOCATED_SIZE", "ALLOCATION_LIMIT"}
+	serviceCols := []string{"HOST", "PORT", "SERVICE_NAME", "TOTAL_MEMORY_USED_SIZE", "PEAK_MEMORY_USED",
+		"ALLOCATION_LIMIT", "EFFECTIVE_ALLOCATION_LIMIT"}
+	heapCols := []string{"HOST", "PORT", "CATEGORY", "EXCLUSIVE_SIZE_IN_USE"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		topN uint
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *MemoryUsage
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{2}, &MemoryUsage{
+			[]HostMemory{{"hana01", 4096, 8192, 6144, 16384}},
+			[]ServiceMemory{
+				{"hana01", 30001, "nameserver", 1024, 2048, 16384, 12288},
+				{"hana01", 30003, "indexserver", 3072, 6144, 16384, 13312}},
+			[]HeapAllocator{
+				{"hana01", 30003, "Pool/ColumnStore/Main/Uncompressed", 2048},
+				{"hana01", 30003, "Pool/Statistics", 512}}}, false},
+		{"GoodNoHeap", fields{db1, ""}, args{0}, &MemoryUsage{
+			[]HostMemory{{"hana01", 4096, 8192, 6144, 16384}},
+			[]ServiceMemory{{"hana01", 30003, "indexserver", 3072, 6144, 16384, 13312}},
+			[]HeapAllocator{}}, false},
+		{"HeapDbError", fields{db1, ""}, args{5}, nil, true},
+		{"HeapScanError", fields{db1, ""}, args{5}, nil, true},
+		{"ServiceDbError", fields{db1, ""}, args{5}, nil, true},
+		{"ServiceScanError", fields{db1, ""}, args{5}, nil, true},
+		{"HostDbError", fields{db1, ""}, args{5}, nil, true},
+		{"HostScanError", fields{db1, ""}, args{5}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, 8192, 6144, 16384)
+			rows2 := mock.NewRows(serviceCols)
+			rows2.AddRow("hana01", 30001, "nameserver", 1024, 2048, 16384, 12288)
+			rows2.AddRow("hana01", 30003, "indexserver", 3072, 6144, 16384, 13312)
+			rows3 := mock.NewRows(heapCols)
+			rows3.AddRow("hana01", 30003, "Pool/ColumnStore/Main/Uncompressed", 2048)
+			rows3.AddRow("hana01", 30003, "Pool/Statistics", 512)
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetServiceMemory).WillReturnRows(rows2)
+			mock.ExpectQuery(f_GetTopHeapAllocators(tt.args.topN)).WillReturnRows(rows3)
+		case "GoodNoHeap":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, 8192, 6144, 16384)
+			rows2 := mock.NewRows(serviceCols).AddRow("hana01", 30003, "indexserver", 3072, 6144, 16384, 13312)
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetServiceMemory).WillReturnRows(rows2)
+		case "HeapDbError":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, 8192, 6144, 16384)
+			rows2 := mock.NewRows(serviceCols).AddRow("hana01", 30003, "indexserver", 3072, 6144, 16384, 13312)
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetServiceMemory).WillReturnRows(rows2)
+			mock.ExpectQuery(f_GetTopHeapAllocators(tt.args.topN)).WillReturnError(fmt.Errorf("DbError"))
+		case "HeapScanError":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, 8192, 6144, 16384)
+			rows2 := mock.NewRows(serviceCols).AddRow("hana01", 30003, "indexserver", 3072, 6144, 16384, 13312)
+			rows3 := mock.NewRows(heapCols).AddRow("hana01", 30003, "Pool/Statistics", "512.5")
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetServiceMemory).WillReturnRows(rows2)
+			mock.ExpectQuery(f_GetTopHeapAllocators(tt.args.topN)).WillReturnRows(rows3)
+		case "ServiceDbError":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, 8192, 6144, 16384)
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetServiceMemory).WillReturnError(fmt.Errorf("DbError"))
+		case "ServiceScanError":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, 8192, 6144, 16384)
+			rows2 := mock.NewRows(serviceCols).AddRow("hana01", 30003, "indexserver", "-3072", 6144, 16384, 13312)
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetServiceMemory).WillReturnRows(rows2)
+		case "HostDbError":
+			mock.ExpectQuery(q_GetHostMemory).WillReturnError(fmt.Errorf("DbError"))
+		case "HostScanError":
+			rows1 := mock.NewRows(hostCols).AddRow("hana01", 4096, "8192.1", 6144, 16384)
+			mock.ExpectQuery(q_GetHostMemory).WillReturnRows(rows1)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetMemoryUsage(tt.args.topN)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetMemoryUsage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetMemoryUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetTableSizes(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"SCHEMA_NAME", "TABLE_NAME", "STORE_TYPE", "MEMORY_SIZE", "DISK_SIZE", "RECORD_COUNT", "DELTA_SIZE", "PARTITIONS"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		filter TableSizeFilter
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []TableSize
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{TableSizeFilter{"", "", 2}}, []TableSize{
+			{"SAPABAP1", "BALDAT", "COLUMN", 40960, 81920, 1000000, 1024, 4},
+			{"SAPABAP1", "VBDATA", "ROW", 20480, 20480, 5000, 0, 1}}, false},
+		{"GoodSchemaColumn", fields{db1, ""}, args{TableSizeFilter{"SAPABAP1", "COLUMN", 0}}, []TableSize{
+			{"SAPABAP1", "BALDAT", "COLUMN", 40960, 81920, 1000000, 1024, 4}}, false},
+		{"InvalidStoreType", fields{db1, ""}, args{TableSizeFilter{"", "DOCUMENT", 10}}, nil, true},
+		{"DbError", fields{db1, ""}, args{TableSizeFilter{"", "", 10}}, nil, true},
+		{"ScanError", fields{db1, ""}, args{TableSizeFilter{"", "ROW", 10}}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		q := f_GetTableSizes(tt.args.filter.SchemaName, tt.args.filter.StoreType, tt.args.filter.Limit)
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow("SAPABAP1", "BALDAT", "COLUMN", 40960, 81920, 1000000, 1024, 4)
+			rows.AddRow("SAPABAP1", "VBDATA", "ROW", 20480, 20480, 5000, 0, 1)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		case "GoodSchemaColumn":
+			rows := mock.NewRows(cols)
+			rows.AddRow("SAPABAP1", "BALDAT", "COLUMN", 40960, 81920, 1000000, 1024, 4)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		case "InvalidStoreType":
+			/*No query expected*/
+		case "DbError":
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow("SAPABAP1", "VBDATA", "ROW", "20480.5", 20480, 5000, 0, 1)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetTableSizes(tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetTableSizes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetTableSizes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetTableUnloads(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"UNLOAD_TIME", "HOST", "PORT", "SCHEMA_NAME", "TABLE_NAME", "COLUMN_NAME", "REASON"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		days uint
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []TableUnload
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{7}, []TableUnload{
+			{genTime, "hana01", 30003, "SAPABAP1", "BALDAT", "", "LOW MEMORY"},
+			{genTime, "hana01", 30003, "SAPABAP1", "CDPOS", "TABKEY", "UNUSED RESOURCE"}}, false},
+		{"NoRows", fields{db1, ""}, args{1}, []TableUnload{}, false},
+		{"DbError", fields{db1, ""}, args{7}, nil, true},
+		{"ScanError", fields{db1, ""}, args{7}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow(genTime, "hana01", 30003, "SAPABAP1", "BALDAT", "", "LOW MEMORY")
+			rows.AddRow(genTime, "hana01", 30003, "SAPABAP1", "CDPOS", "TABKEY", "UNUSED RESOURCE")
+			mock.ExpectQuery(f_GetTableUnloads(tt.args.days)).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(f_GetTableUnloads(tt.args.days)).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(f_GetTableUnloads(tt.args.days)).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow("not a time", "hana01", 30003, "SAPABAP1", "BALDAT", "", "LOW MEMORY")
+			mock.ExpectQuery(f_GetTableUnloads(tt.args.days)).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetTableUnloads(tt.args.days)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetTableUnloads() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetTableUnloads() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetDeltaMergeCandidates(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"SCHEMA_NAME", "TABLE_NAME", "DELTA_SIZE", "DELTA_RECORDS", "MAIN_SIZE", "LAST_MERGE_TIME", "FAILED_MERGES"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		thresholds DeltaMergeThresholds
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []DeltaMergeCandidate
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{DeltaMergeThresholds{1024, 100}}, []DeltaMergeCandidate{
+			{"SAPABAP1", "BALDAT", 40960, 10000, 81920, genTime, 0},
+			{"SAPABAP1", "CDPOS", 2048, 200, 4096, time.Time{}, 3}}, false},
+		{"NoRows", fields{db1, ""}, args{DeltaMergeThresholds{0, 0}}, []DeltaMergeCandidate{}, false},
+		{"DbError", fields{db1, ""}, args{DeltaMergeThresholds{1024, 100}}, nil, true},
+		{"ScanError", fields{db1, ""}, args{DeltaMergeThresholds{1024, 100}}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		q := f_GetDeltaMergeCandidates(tt.args.thresholds.MinDeltaBytes, tt.args.thresholds.MinDeltaRecords)
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow("SAPABAP1", "BALDAT", 40960, 10000, 81920, genTime, 0)
+			rows.AddRow("SAPABAP1", "CDPOS", 2048, 200, 4096, nil, 3)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(q).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow("SAPABAP1", "BALDAT", "-40960", 10000, 81920, genTime, 0)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetDeltaMergeCandidates(tt.args.thresholds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetDeltaMergeCandidates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetDeltaMergeCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_ListStatServerAlerts(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"ALERT_ID", "ALERT_NAME", "ALERT_RATING", "HOST", "PORT", "ALERT_TIMESTAMP", "ALERT_DETAILS", "ALERT_USERACTION"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		filter StatServerAlertFilter
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []StatServerAlert
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{StatServerAlertFilter{4, genTime.AddDate(0, 0, -7), time.Time{}, 10}}, []StatServerAlert{
+			{2, "Disk usage", 4, "hana01", 30003, genTime, "Disk usage at 95%", "Investigate disk usage"},
+			{28, "Savepoint duration", 5, "", 0, genTime, "", ""}}, false},
+		{"NoRows", fields{db1, ""}, args{StatServerAlertFilter{}}, []StatServerAlert{}, false},
+		{"DbError", fields{db1, ""}, args{StatServerAlertFilter{}}, nil, true},
+		{"ScanError", fields{db1, ""}, args{StatServerAlertFilter{}}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		f := tt.args.filter
+		q := f_ListStatServerAlerts(f.MinRating, f.From, f.To, f.Limit)
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow(2, "Disk usage", 4, "hana01", 30003, genTime, "Disk usage at 95%", "Investigate disk usage")
+			rows.AddRow(28, "Savepoint duration", 5, "", 0, genTime, "", "")
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(q).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow("two", "Disk usage", 4, "hana01", 30003, genTime, "", "")
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.ListStatServerAlerts(tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.ListStatServerAlerts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.ListStatServerAlerts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_SummariseStatServerAlerts(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"ALERT_ID", "ALERT_NAME", "COUNT", "MAX_RATING", "FIRST_TIMESTAMP", "LAST_TIMESTAMP"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		filter StatServerAlertFilter
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []StatServerAlertSummary
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{StatServerAlertFilter{MinRating: 3}}, []StatServerAlertSummary{
+			{28, "Savepoint duration", 12, 5, genTime.AddDate(0, 0, -1), genTime},
+			{2, "Disk usage", 400, 4, genTime.AddDate(0, 0, -30), genTime}}, false},
+		{"NoRows", fields{db1, ""}, args{StatServerAlertFilter{}}, []StatServerAlertSummary{}, false},
+		{"DbError", fields{db1, ""}, args{StatServerAlertFilter{}}, nil, true},
+		{"ScanError", fields{db1, ""}, args{StatServerAlertFilter{}}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		f := tt.args.filter
+		q := f_GetStatServerAlertSummary(f.MinRating, f.From, f.To)
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow(28, "Savepoint duration", 12, 5, genTime.AddDate(0, 0, -1), genTime)
+			rows.AddRow(2, "Disk usage", 400, 4, genTime.AddDate(0, 0, -30), genTime)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(q).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols)
+			rows.AddRow(28, "Savepoint duration", "-12", 5, genTime, genTime)
+			mock.ExpectQuery(q).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.SummariseStatServerAlerts(tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.SummariseStatServerAlerts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.SummariseStatServerAlerts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetStatisticsHistorySizes(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"TABLE_NAME", "RECORD_COUNT", "TABLE_SIZE"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []StatisticsHistoryTable
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, []StatisticsHistoryTable{
+			{"HOST_SQL_PLAN_CACHE_BASE", 1000000, 409600000},
+			{"GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 204800}}, false},
+		{"NoRows", fields{db1, ""}, []StatisticsHistoryTable{}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols)
+			rows.AddRow("HOST_SQL_PLAN_CACHE_BASE", 1000000, 409600000)
+			rows.AddRow("GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 204800)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(rows)
+		case "NoRows":
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(mock.NewRows(cols))
+		case "DbError":
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols).AddRow("HOST_SQL_PLAN_CACHE_BASE", "-1", 409600000)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetStatisticsHistorySizes()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetStatisticsHistorySizes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetStatisticsHistorySizes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetAuditLogStats(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"ENTRIES", "OLDEST", "NEWEST", "BYTES"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *AuditLogStats
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &AuditLogStats{50000, genTime.AddDate(-1, 0, 0), genTime, 1024000}, false},
+		{"GoodEmpty", fields{db1, ""}, &AuditLogStats{0, time.Time{}, time.Time{}, 0}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols).AddRow(50000, genTime.AddDate(-1, 0, 0), genTime, 1024000)
+			mock.ExpectQuery(q_GetAuditLogStats).WillReturnRows(rows)
+		case "GoodEmpty":
+			rows := mock.NewRows(cols).AddRow(0, nil, nil, 0)
+			mock.ExpectQuery(q_GetAuditLogStats).WillReturnRows(rows)
+		case "DbError":
+			mock.ExpectQuery(q_GetAuditLogStats).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols).AddRow("-1", genTime, genTime, 1024000)
+			mock.ExpectQuery(q_GetAuditLogStats).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetAuditLogStats()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetAuditLogStats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetAuditLogStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetSqlPlanCacheStats(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *SqlPlanCacheStats
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &SqlPlanCacheStats{5000, 1024000, 4096000}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows([]string{"PLANS", "BYTES", "CAPACITY"}).AddRow(5000, 1024000, 4096000)
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(rows)
+		case "DbError":
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows([]string{"PLANS", "BYTES", "CAPACITY"}).AddRow("-1", 1024000, 4096000)
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetSqlPlanCacheStats()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetSqlPlanCacheStats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetSqlPlanCacheStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetExpensiveStatementsStats(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *ExpensiveStatementsStats
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &ExpensiveStatementsStats{30000, 2048000}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows([]string{"ENTRIES", "BYTES"}).AddRow(30000, 2048000)
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnRows(rows)
+		case "DbError":
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows([]string{"ENTRIES", "BYTES"}).AddRow(30000, "2048000.5")
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetExpensiveStatementsStats()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetExpensiveStatementsStats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetExpensiveStatementsStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetLatestBackups(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"FULL_BACKUP", "LOG_BACKUP", "CURRENT_TIME"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *LatestBackups
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &LatestBackups{genTime.AddDate(0, 0, -1), genTime.Add(-15 * time.Minute), genTime}, false},
+		{"GoodEmpty", fields{db1, ""}, &LatestBackups{time.Time{}, time.Time{}, genTime}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols).AddRow(genTime.AddDate(0, 0, -1), genTime.Add(-15*time.Minute), genTime)
+			mock.ExpectQuery(q_GetLatestBackups).WillReturnRows(rows)
+		case "GoodEmpty":
+			rows := mock.NewRows(cols).AddRow(nil, nil, genTime)
+			mock.ExpectQuery(q_GetLatestBackups).WillReturnRows(rows)
+		case "DbError":
+			mock.ExpectQuery(q_GetLatestBackups).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols).AddRow("yesterday", genTime, genTime)
+			mock.ExpectQuery(q_GetLatestBackups).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetLatestBackups()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetLatestBackups() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetLatestBackups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetBackupSummaryFiltered(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	week := BackupFilter{From: genTime.AddDate(0, 0, -7), To: genTime}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		filter  BackupFilter
+		want    *BackupSummary
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, week, &BackupSummary{
+			BackupCatalogEntries:     20,
+			FullBackups:              2,
+			LogBackups:               16,
+			IncrementalBackups:       1,
+			DataSnapshots:            1,
+			SizeOfFullBackupsBytes:   2048,
+			SizeOfLogBackupBytes:     160,
+			SizeOfIncrementalBackups: 512,
+			SizeOfDataSnapshots:      0,
+			SizeOfBackupCatalog:      4096,
+			OldestFullBackupDate:     genTime.AddDate(0, 0, -7),
+			OldestLogBackupDate:      genTime.AddDate(0, 0, -6),
+			CurrentDbTime:            genTime,
+			FullBackupDates:          BackupDates{genTime.AddDate(0, 0, -7), genTime.AddDate(0, 0, -1), 7 * 86400, 86400},
+			LogBackupDates:           BackupDates{genTime.AddDate(0, 0, -6), genTime.Add(-time.Hour), 6 * 86400, 3600},
+			IncrementalBackupDates:   BackupDates{genTime.AddDate(0, 0, -3), genTime.AddDate(0, 0, -3), 3 * 86400, 3 * 86400},
+			DataSnapshotDates:        BackupDates{genTime.AddDate(0, 0, -2), genTime.AddDate(0, 0, -2), 2 * 86400, 2 * 86400},
+			Types: map[string]BackupTypeStats{
+				EntryTypeFullBackup:        {2, 2048, BackupDates{genTime.AddDate(0, 0, -7), genTime.AddDate(0, 0, -1), 7 * 86400, 86400}},
+				EntryTypeLogBackup:         {16, 160, BackupDates{genTime.AddDate(0, 0, -6), genTime.Add(-time.Hour), 6 * 86400, 3600}},
+				EntryTypeIncrementalBackup: {1, 512, BackupDates{genTime.AddDate(0, 0, -3), genTime.AddDate(0, 0, -3), 3 * 86400, 3 * 86400}},
+				EntryTypeDataSnapshot:      {1, 0, BackupDates{genTime.AddDate(0, 0, -2), genTime.AddDate(0, 0, -2), 2 * 86400, 2 * 86400}},
+			},
+		}, false},
+		{"GoodNoCatalogBackup", fields{db1, ""}, week, &BackupSummary{CurrentDbTime: genTime, Types: map[string]BackupTypeStats{}}, false},
+		{"InvalidBackupID", fields{db1, ""}, BackupFilter{BeforeBackupID: "1 OR 1=1"}, nil, true},
+		{"TypeUnexpectedResult", fields{db1, ""}, week, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(backupSummaryColumns)
+			rows.AddRow("complete data backup", 2, 2048, genTime.AddDate(0, 0, -7), genTime.AddDate(0, 0, -1), 4096, genTime)
+			rows.AddRow("data snapshot", 1, 0, genTime.AddDate(0, 0, -2), genTime.AddDate(0, 0, -2), 4096, genTime)
+			rows.AddRow("incremental data backup", 1, 512, genTime.AddDate(0, 0, -3), genTime.AddDate(0, 0, -3), 4096, genTime)
+			rows.AddRow("log backup", 16, 160, genTime.AddDate(0, 0, -6), genTime.Add(-time.Hour), 4096, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(tt.filter)).WillReturnRows(rows)
+		case "GoodNoCatalogBackup":
+			rows := mock.NewRows(backupSummaryColumns).AddRow(nil, 0, 0, nil, nil, 0, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(tt.filter)).WillReturnRows(rows)
+		case "InvalidBackupID":
+			/*No queries expected*/
+		case "TypeUnexpectedResult":
+			rows := mock.NewRows(backupSummaryColumns).AddRow("tape", 1, 0, genTime, genTime, 0, genTime)
+			mock.ExpectQuery(f_GetBackupSummary(tt.filter)).WillReturnRows(rows)
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			/*Unknown types are only errors in strict mode*/
+			h.SetStrictBackupTypes(strings.HasSuffix(tt.name, "UnexpectedResult"))
+			got, err := h.GetBackupSummaryFiltered(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetBackupSummaryFiltered() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetBackupSummaryFiltered() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+/******************************************************************************/
+/* The backup summary took six sequential statements before it was gathered   */
+/* in a single statement. The previous queries and fetch path are kept here   */
+/* so that the benchmark below compares against what the client used to do.   */
+/******************************************************************************/
+
+func f_GetBackupCatalogEntryCount(f BackupFilter) string {
+	return "SELECT " +
+		"COUNT(CAT.BACKUP_ID) AS COUNT " +
+		"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT " +
+		backupFilterWhere(f, "CAT")
+}
+
+func f_GetBackupCount(f BackupFilter) string {
+	return "SELECT " +
+		"COUNT(CAT.ENTRY_ID) AS COUNT, " +
+		"CAT.ENTRY_TYPE_NAME " +
+		"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT " +
+		backupFilterWhere(f, "CAT") +
+		"GROUP BY CAT.ENTRY_TYPE_NAME"
+}
+
+func f_GetBackupSizes(f BackupFilter) string {
+	return "SELECT " +
+		"CAT.ENTRY_TYPE_NAME AS TYPE, " +
+		"SUM(FILES.BACKUP_SIZE) AS BYTES " +
+		"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT " +
+		"LEFT JOIN \"SYS\".\"M_BACKUP_CATALOG_FILES\" AS FILES " +
+		"ON CAT.BACKUP_ID = FILES.BACKUP_ID " +
+		backupFilterWhere(f, "CAT") +
+		"GROUP BY CAT.ENTRY_TYPE_NAME"
+}
+
+func f_GetBackupDates(f BackupFilter) string {
+	return "SELECT " +
+		"CAT.ENTRY_TYPE_NAME, " +
+		"MIN(CAT.UTC_START_TIME) AS OLDEST, " +
+		"MAX(CAT.UTC_START_TIME) AS NEWEST " +
+		"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT " +
+		backupFilterWhere(f, "CAT") +
+		"GROUP BY CAT.ENTRY_TYPE_NAME"
+}
+
+// fetchBackupStatsSequential is the fetch path used before the backup summary
+// was gathered in a single statement
+func (h *HanaUtilClient) fetchBackupStatsSequential(ctx context.Context, filter BackupFilter) (*BackupSummary, error) {
+	bs := BackupSummary{Types: make(map[string]BackupTypeStats)}
+
+	r1 := h.queryRow(ctx, "f_GetBackupCatalogEntryCount", f_GetBackupCatalogEntryCount(filter))
+	err := r1.Scan(&bs.BackupCatalogEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	r2, err := h.query(ctx, "f_GetBackupCount", f_GetBackupCount(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer r2.Close()
+	for r2.Next() {
+		var tmpCount uint64
+		var tmpType string
+		err = r2.Scan(&tmpCount, &tmpType)
+		if err != nil {
+			return nil, err
+		}
+		t := bs.Types[tmpType]
+		t.Count = tmpCount
+		bs.Types[tmpType] = t
+	}
+
+	r3, err := h.query(ctx, "f_GetBackupSizes", f_GetBackupSizes(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer r3.Close()
+	for r3.Next() {
+		var tmpType string
+		var tmpCount uint64
+		err = r3.Scan(&tmpType, &tmpCount)
+		if err != nil {
+			return nil, err
+		}
+		t := bs.Types[tmpType]
+		t.SizeBytes = tmpCount
+		bs.Types[tmpType] = t
+	}
+
+	r4, err := h.query(ctx, "f_GetBackupDates", f_GetBackupDates(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer r4.Close()
+	for r4.Next() {
+		var tmpType string
+		var oldest, newest time.Time
+		err = r4.Scan(&tmpType, &oldest, &newest)
+		if err != nil {
+			return nil, err
+		}
+		t := bs.Types[tmpType]
+		t.Dates.Oldest, t.Dates.Newest = oldest, newest
+		bs.Types[tmpType] = t
+	}
+
+	var backupCatalogSize uint64
+	r5 := h.queryRow(ctx, "f_GetBackupCatalogSize", f_GetBackupCatalogSize(filter))
+	err = r5.Scan(&backupCatalogSize)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	bs.SizeOfBackupCatalog = backupCatalogSize
+
+	r6 := h.queryRow(ctx, "q_GetDbCurrentUtcTime", q_GetDbCurrentUtcTime)
+	err = r6.Scan(&bs.CurrentDbTime)
+	if err != nil {
+		return nil, err
+	}
+
+	bs.setTypedFields()
+	return &bs, nil
+}
+
+// expectBackupSummarySequential adds the statements of the previous fetch path
+// to 'mock', each delayed by 'latency'
+func expectBackupSummarySequential(mock sqlmock.Sqlmock, latency time.Duration, genTime time.Time) {
+	f := BackupFilter{}
+	mock.ExpectQuery(f_GetBackupCatalogEntryCount(f)).WillDelayFor(latency).
+		WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(100))
+	mock.ExpectQuery(f_GetBackupCount(f)).WillDelayFor(latency).
+		WillReturnRows(mock.NewRows([]string{"COUNT", "ENTRY_TYPE_NAME"}).
+			AddRow(10, "complete data backup").AddRow(90, "log backup"))
+	mock.ExpectQuery(f_GetBackupSizes(f)).WillDelayFor(latency).
+		WillReturnRows(mock.NewRows([]string{"TYPE", "BYTES"}).
+			AddRow("complete data backup", 1024000).AddRow("log backup", 512000))
+	mock.ExpectQuery(f_GetBackupDates(f)).WillDelayFor(latency).
+		WillReturnRows(mock.NewRows([]string{"ENTRY_TYPE_NAME", "OLDEST", "NEWEST"}).
+			AddRow("complete data backup", genTime, genTime).AddRow("log backup", genTime, genTime))
+	mock.ExpectQuery(f_GetBackupCatalogSize(f)).WillDelayFor(latency).
+		WillReturnRows(mock.NewRows([]string{"BACKUP_SIZE"}).AddRow(10240))
+	mock.ExpectQuery(q_GetDbCurrentUtcTime).WillDelayFor(latency).
+		WillReturnRows(mock.NewRows([]string{"CURRENT_TIME"}).AddRow(genTime))
+}
+
+// expectBackupSummary adds the single statement of the backup summary to
+// 'mock', delayed by 'latency', returning the same catalog as
+// expectBackupSummarySequential
+func expectBackupSummary(mock sqlmock.Sqlmock, latency time.Duration, genTime time.Time) {
+	rows := mock.NewRows(backupSummaryColumns)
+	rows.AddRow("complete data backup", 10, 1024000, genTime, genTime, 10240, genTime)
+	rows.AddRow("log backup", 90, 512000, genTime, genTime, 10240, genTime)
+	mock.ExpectQuery(f_GetBackupSummary(BackupFilter{})).WillDelayFor(latency).WillReturnRows(rows)
+}
+
+// The benchmark is only a fair comparison if both paths build the same summary
+func Test_hanaUtilClient_fetchBackupStatsSequential(t *testing.T) {
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db1.Close()
+	h := &HanaUtilClient{db: db1}
+
+	expectBackupSummarySequential(mock, 0, genTime)
+	want, err := h.fetchBackupStatsSequential(context.Background(), BackupFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBackupSummary(mock, 0, genTime)
+	got, err := h.GetBackupSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HanaUtilClient.GetBackupSummary() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// BenchmarkHanaUtilClient_GetBackupSummary measures a backup summary over a
+// link with the given round trip latency, simulated by delaying each
+// statement. Sequential runs the previous fetch path at the same latency.
+func BenchmarkHanaUtilClient_GetBackupSummary(b *testing.B) {
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, latency := range []time.Duration{0, time.Millisecond, 5 * time.Millisecond} {
+		b.Run(fmt.Sprintf("Summary/latency=%s", latency), func(b *testing.B) {
+			db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer db1.Close()
+			h := &HanaUtilClient{db: db1}
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				expectBackupSummary(mock, latency, genTime)
+				b.StartTimer()
+				_, err := h.GetBackupSummary()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(1, "round-trips/op")
+		})
+		b.Run(fmt.Sprintf("Sequential/latency=%s", latency), func(b *testing.B) {
+			db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer db1.Close()
+			h := &HanaUtilClient{db: db1}
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				expectBackupSummarySequential(mock, latency, genTime)
+				b.StartTimer()
+				_, err := h.fetchBackupStatsSequential(ctx, BackupFilter{})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(6, "round-trips/op")
+		})
+	}
+}
@@ -7,25 +7,55 @@ a new client.
 
 import (
 	"database/sql"
+	"database/sql/driver"
 
 	_ "github.com/SAP/go-hdb/driver"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type HanaUtilClient struct {
-	db  *sql.DB //non-exported database connection
-	dsn string  //non-exported dsn, used to create connection
+	db                *sql.DB      //non-exported database connection
+	dsn               string       //non-exported dsn, used to create connection
+	tracer            trace.Tracer //non-exported tracer, nil when tracing is disabled
+	strictBackupTypes bool         //non-exported, fail on unknown backup types
+	parent            trace.Span   //non-exported, parent of operation spans, see withParent
 }
 
 func NewClient(dsn string) *HanaUtilClient {
 	/*should we do some basic dsn format testing?*/
-	return &HanaUtilClient{nil, dsn}
+	return &HanaUtilClient{db: nil, dsn: dsn}
+}
+
+// NewClientFromDB returns a client that uses an existing database handle, such
+// as one shared with the rest of an application. Connect only checks that the
+// database can be reached and Close closes 'db'.
+func NewClientFromDB(db *sql.DB) *HanaUtilClient {
+	return &HanaUtilClient{db: db}
+}
+
+// NewClientFromConnector returns a client that connects to the database using
+// 'c', for example a connector created with the go-hdb driver package that
+// holds TLS or session settings that cannot be expressed in a DSN.
+func NewClientFromConnector(c driver.Connector) *HanaUtilClient {
+	return NewClientFromDB(sql.OpenDB(c))
+}
+
+// SetStrictBackupTypes sets whether backup summaries fail with the error
+// 'UnexpectedBackupType' when the backup catalog holds an entry type that has
+// no fields of its own in BackupSummary. By default such types are only
+// reported in BackupSummary.Types.
+func (h *HanaUtilClient) SetStrictBackupTypes(strict bool) {
+	h.strictBackupTypes = strict
 }
 
 func (h *HanaUtilClient) Connect() error {
 	var err error
-	h.db, err = sql.Open("hdb", h.dsn)
-	if err != nil {
-		return err
+	/*Clients created from an existing database are already open*/
+	if h.db == nil || h.dsn != "" {
+		h.db, err = sql.Open("hdb", h.dsn)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = h.db.Ping()
@@ -0,0 +1,55 @@
+package hanautil
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestNewClientFromDB(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual), sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+
+	mock.ExpectPing()
+	mock.ExpectQuery(q_GetHanaVersion).WillReturnRows(mock.NewRows([]string{"VERSION"}).AddRow("2.00.070.00"))
+	mock.ExpectClose()
+
+	h := NewClientFromDB(db1)
+	err = h.Connect()
+	if err != nil {
+		t.Fatalf("HanaUtilClient.Connect() error = %v", err)
+	}
+	got, err := h.GetVersion()
+	if err != nil || got != "2.00.070.00" {
+		t.Errorf("HanaUtilClient.GetVersion() = %v, %v, want 2.00.070.00", got, err)
+	}
+	err = h.Close()
+	if err != nil {
+		t.Errorf("HanaUtilClient.Close() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// failingConnector is a driver.Connector that can never connect
+type failingConnector struct{}
+
+func (failingConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, fmt.Errorf("ConnectorError")
+}
+
+func (failingConnector) Driver() driver.Driver { return nil }
+
+func TestNewClientFromConnector(t *testing.T) {
+	h := NewClientFromConnector(failingConnector{})
+	err := h.Connect()
+	if err == nil || err.Error() != "ConnectorError" {
+		t.Errorf("HanaUtilClient.Connect() error = %v, want ConnectorError", err)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+	"github.com/mr-stringer/hanautil/metrics"
+)
+
+/******************************************************************************/
+/* This file contains the CLI commands. Each command calls the library and    */
+/* writes its result as text or json.                                         */
+/******************************************************************************/
+
+// Output formats
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTable = "table"
+)
+
+var commands = []command{
+	{"version", "show the HANA database version", 0, runVersion},
+	{"traces list", "list trace files older than -days", flagDays, runTracesList},
+	{"traces remove", "remove trace files older than -days, or a single -trace-host and -trace-file", flagDays | flagRequireDays | flagDryRun | flagTraceFile, runTracesRemove},
+	{"backup summary", "summarise the backup catalog", 0, runBackupSummary},
+	{"backup trend", "show backup catalog growth over -days and forecast when it exceeds -threshold", flagTrend, runBackupTrend},
+	{"backup truncate", "truncate the backup catalog retaining -days", flagDays | flagRequireDays | flagComplete | flagDryRun, runBackupTruncate},
+	{"alerts count", "count statistics server alerts older than -days", flagDays, runAlertsCount},
+	{"alerts purge", "remove statistics server alerts older than -days", flagDays | flagRequireDays | flagDryRun, runAlertsPurge},
+	{"log stats", "show free and non-free log segments", 0, runLogStats},
+	{"log reclaim", "reclaim free log segments", flagDryRun, runLogReclaim},
+	{"exporter", "serve Prometheus metrics on -listen until stopped", flagExporter, runExporter},
+}
+
+// write writes v as json, csv or a table, or calls text with a tabwriter for
+// text output
+func write(w io.Writer, o *options, v any, text func(tw *tabwriter.Writer)) error {
+	switch o.output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputCSV:
+		return hanautil.WriteCSV(w, v)
+	case outputTable:
+		return hanautil.WriteTable(w, v)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	text(tw)
+	return tw.Flush()
+}
+
+func runVersion(h hanautil.Client, o *options, w io.Writer) error {
+	v, err := h.GetVersion()
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Version string `json:"version"`
+	}{v}
+	return write(w, o, out, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, v)
+	})
+}
+
+func writeTraceFiles(w io.Writer, o *options, tf []hanautil.TraceFile) error {
+	return write(w, o, tf, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "HOST\tFILE\tBYTES\tLAST MODIFIED")
+		for _, t := range tf {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", t.Hostname, t.FileName, t.FileSizeBytes, t.LastModified.Format(time.RFC3339))
+		}
+	})
+}
+
+func runTracesList(h hanautil.Client, o *options, w io.Writer) error {
+	tf, err := h.GetTraceFiles(o.days)
+	if err != nil {
+		return err
+	}
+	return writeTraceFiles(w, o, tf)
+}
+
+// traceRemoval is the result of removing a single trace file
+type traceRemoval struct {
+	Hostname string `json:"hostname"`
+	FileName string `json:"file_name"`
+	Removed  bool   `json:"removed"`
+	Error    string `json:"error,omitempty"`
+}
+
+func runTracesRemove(h hanautil.Client, o *options, w io.Writer) error {
+	var tf []hanautil.TraceFile
+	switch {
+	case o.host != "" && o.file != "":
+		tf = []hanautil.TraceFile{{Hostname: o.host, FileName: o.file}}
+	case o.host != "" || o.file != "":
+		return fmt.Errorf("%w: -trace-host and -trace-file must be given together", errUsage)
+	default:
+		var err error
+		tf, err = h.GetTraceFiles(o.days)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.dryRun {
+		return writeTraceFiles(w, o, tf)
+	}
+
+	res := make([]traceRemoval, 0, len(tf))
+	for _, t := range tf {
+		r := traceRemoval{Hostname: t.Hostname, FileName: t.FileName, Removed: true}
+		err := h.RemoveTraceFile(t.Hostname, t.FileName)
+		if err != nil {
+			r.Removed = false
+			r.Error = err.Error()
+		}
+		res = append(res, r)
+	}
+	return write(w, o, res, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "HOST\tFILE\tREMOVED\tERROR")
+		for _, r := range res {
+			fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", r.Hostname, r.FileName, r.Removed, r.Error)
+		}
+	})
+}
+
+func writeBackupSummary(w io.Writer, o *options, bs *hanautil.BackupSummary) error {
+	return write(w, o, bs, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Catalog entries\t%d\n", bs.BackupCatalogEntries)
+		fmt.Fprintf(tw, "Full backups\t%d\t%d bytes\n", bs.FullBackups, bs.SizeOfFullBackupsBytes)
+		fmt.Fprintf(tw, "Log backups\t%d\t%d bytes\n", bs.LogBackups, bs.SizeOfLogBackupBytes)
+		fmt.Fprintf(tw, "Incremental backups\t%d\t%d bytes\n", bs.IncrementalBackups, bs.SizeOfIncrementalBackups)
+		fmt.Fprintf(tw, "Differential backups\t%d\t%d bytes\n", bs.DifferentialBackups, bs.SizeOfDifferentialBackups)
+		fmt.Fprintf(tw, "Log missing\t%d\t%d bytes\n", bs.LogMissing, bs.SizeOfLogMissing)
+		fmt.Fprintf(tw, "Data snapshots\t%d\t%d bytes\n", bs.DataSnapshots, bs.SizeOfDataSnapshots)
+		for _, name := range bs.OtherTypes() {
+			fmt.Fprintf(tw, "Other: %s\t%d\t%d bytes\n", name, bs.Types[name].Count, bs.Types[name].SizeBytes)
+		}
+		fmt.Fprintf(tw, "Backup catalog\t\t%d bytes\n", bs.SizeOfBackupCatalog)
+		fmt.Fprintf(tw, "Oldest full backup\t%s\n", bs.OldestFullBackupDate.Format(time.RFC3339))
+		fmt.Fprintf(tw, "Newest full backup\t%s\n", bs.FullBackupDates.Newest.Format(time.RFC3339))
+		fmt.Fprintf(tw, "Oldest log backup\t%s\n", bs.OldestLogBackupDate.Format(time.RFC3339))
+		fmt.Fprintf(tw, "Newest log backup\t%s\n", bs.LogBackupDates.Newest.Format(time.RFC3339))
+		fmt.Fprintf(tw, "Database time\t%s\n", bs.CurrentDbTime.Format(time.RFC3339))
+	})
+}
+
+func runBackupSummary(h hanautil.Client, o *options, w io.Writer) error {
+	bs, err := h.GetBackupSummary()
+	if err != nil {
+		return err
+	}
+	return writeBackupSummary(w, o, bs)
+}
+
+func runBackupTrend(h hanautil.Client, o *options, w io.Writer) error {
+	bt, err := h.GetBackupCatalogTrend(o.days, o.threshold)
+	if err != nil {
+		return err
+	}
+	return write(w, o, bt, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Catalog size\t%d bytes\n", bt.CurrentSizeBytes)
+		fmt.Fprintf(tw, "Growth\t%.0f bytes/day\n", bt.GrowthBytesPerDay)
+		if !bt.GrowthSince.IsZero() {
+			fmt.Fprintf(tw, "Growth since\t%s\n", bt.GrowthSince.Format(time.RFC3339))
+		}
+		fmt.Fprintf(tw, "Threshold\t%d bytes\n", bt.ThresholdBytes)
+		switch {
+		case bt.ThresholdExceeded:
+			fmt.Fprintf(tw, "Threshold reached\texceeded\n")
+		case bt.ThresholdTime.IsZero():
+			fmt.Fprintf(tw, "Threshold reached\tnot forecast\n")
+		default:
+			fmt.Fprintf(tw, "Threshold reached\t%s\n", bt.ThresholdTime.Format(time.RFC3339))
+		}
+		fmt.Fprintf(tw, "Database time\t%s\n", bt.CurrentDbTime.Format(time.RFC3339))
+	})
+}
+
+func runBackupTruncate(h hanautil.Client, o *options, w io.Writer) error {
+	if o.dryRun {
+		id, err := h.GetFullBackupId(int(o.days))
+		if err != nil {
+			return fmt.Errorf("no full backup older than %d days found: %w", o.days, err)
+		}
+		bs, err := h.GetBackupSummaryBeforeBackupID(id)
+		if err != nil {
+			return err
+		}
+		return writeBackupSummary(w, o, bs)
+	}
+
+	ts, err := h.TruncateBackupCatalog(int(o.days), o.complete)
+	if err != nil {
+		return err
+	}
+	return write(w, o, ts, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Files removed\t%d\n", ts.FilesRemoved)
+		fmt.Fprintf(tw, "Bytes removed\t%d\n", ts.BytesRemoved)
+	})
+}
+
+func writeAlertCount(w io.Writer, o *options, n uint64) error {
+	out := struct {
+		Alerts uint64 `json:"alerts"`
+	}{n}
+	return write(w, o, out, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Alerts older than %d days\t%d\n", o.days, n)
+	})
+}
+
+func runAlertsCount(h hanautil.Client, o *options, w io.Writer) error {
+	n, err := h.GetStatServerAlerts(o.days)
+	if err != nil {
+		return err
+	}
+	return writeAlertCount(w, o, uint64(n))
+}
+
+func runAlertsPurge(h hanautil.Client, o *options, w io.Writer) error {
+	if o.dryRun {
+		return runAlertsCount(h, o, w)
+	}
+	n, err := h.RemoveStatServerAlerts(o.days)
+	if err != nil {
+		return err
+	}
+	out := struct {
+		AlertsRemoved uint64 `json:"alerts_removed"`
+	}{n}
+	return write(w, o, out, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Alerts removed\t%d\n", n)
+	})
+}
+
+func runLogStats(h hanautil.Client, o *options, w io.Writer) error {
+	ls, err := h.GetLogSegmentStats()
+	if err != nil {
+		return err
+	}
+	return write(w, o, ls, func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "Free segments\t%d\t%d bytes\n", ls.FreeSegments, ls.TotalFreeSegmentBytes)
+		fmt.Fprintf(tw, "Non-free segments\t%d\t%d bytes\n", ls.NonFreeSegments, ls.TotalNonFreeSegmentBytes)
+	})
+}
+
+func runLogReclaim(h hanautil.Client, o *options, w io.Writer) error {
+	if o.dryRun {
+		return runLogStats(h, o, w)
+	}
+	rr, err := h.ReclaimLog()
+	if err != nil {
+		return err
+	}
+	/*CSV and tables hold a row per service*/
+	var v any = rr
+	if o.output == outputCSV || o.output == outputTable {
+		v = rr.Services
+	}
+	return write(w, o, v, func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "HOST\tPORT\tSERVICE\tSEGMENTS RECLAIMED\tBYTES RECLAIMED")
+		for _, s := range rr.Services {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\n", s.Hostname, s.Port, s.ServiceName, s.SegmentsReclaimed(), s.BytesReclaimed())
+		}
+		fmt.Fprintf(tw, "Total\t\t\t%d\t%d\n", rr.SegmentsReclaimed(), rr.BytesReclaimed())
+	})
+}
+
+func runExporter(h hanautil.Client, o *options, w io.Writer) error {
+	c := metrics.NewCollector(h, metrics.Options{
+		TraceRetentionDays: o.traceDays,
+		AlertRetentionDays: o.alertDays,
+		CacheDuration:      o.cache,
+	})
+	handler, err := metrics.NewHandler(c)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "serving metrics on %s/metrics\n", o.listen)
+	return http.ListenAndServe(o.listen, handler)
+}
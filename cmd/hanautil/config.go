@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+/******************************************************************************/
+/* This file resolves the connection details used by the CLI. Details are     */
+/* read from a JSON config file, then environment variables and then flags,   */
+/* with later sources taking precedence.                                      */
+/******************************************************************************/
+
+// Environment variables that may hold connection details
+const (
+	envConfig   = "HANAUTIL_CONFIG"
+	envDSN      = "HANAUTIL_DSN"
+	envHost     = "HANAUTIL_HOST"
+	envPort     = "HANAUTIL_PORT"
+	envUser     = "HANAUTIL_USER"
+	envPassword = "HANAUTIL_PASSWORD"
+)
+
+// connConfig holds the details required to connect to HANA. If DSN is set it
+// is used as is, otherwise a DSN is built from the remaining fields.
+type connConfig struct {
+	DSN      string `json:"dsn"`
+	Host     string `json:"host"`
+	Port     uint   `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// merge overwrites the fields of c with any fields that are set in o
+func (c *connConfig) merge(o connConfig) {
+	if o.DSN != "" {
+		c.DSN = o.DSN
+	}
+	if o.Host != "" {
+		c.Host = o.Host
+	}
+	if o.Port != 0 {
+		c.Port = o.Port
+	}
+	if o.User != "" {
+		c.User = o.User
+	}
+	if o.Password != "" {
+		c.Password = o.Password
+	}
+}
+
+// dsn returns the DSN used to connect to HANA
+func (c *connConfig) dsn() (string, error) {
+	if c.DSN != "" {
+		return c.DSN, nil
+	}
+	if c.Host == "" || c.Port == 0 || c.User == "" {
+		return "", fmt.Errorf("connection details missing, set a dsn or a host, port and user")
+	}
+	u := url.URL{
+		Scheme: "hdb",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   net.JoinHostPort(c.Host, strconv.FormatUint(uint64(c.Port), 10)),
+	}
+	return u.String(), nil
+}
+
+// readConfigFile reads connection details from the JSON file at path
+func readConfigFile(path string) (connConfig, error) {
+	var c connConfig
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return c, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// envConn reads connection details from the environment using getenv
+func envConn(getenv func(string) string) (connConfig, error) {
+	c := connConfig{
+		DSN:      getenv(envDSN),
+		Host:     getenv(envHost),
+		User:     getenv(envUser),
+		Password: getenv(envPassword),
+	}
+	if p := getenv(envPort); p != "" {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return c, fmt.Errorf("invalid %s: %s", envPort, p)
+		}
+		c.Port = uint(port)
+	}
+	return c, nil
+}
+
+// resolveDSN returns the DSN built from the config file, the environment and
+// the flags, in increasing order of precedence. The config file is given by
+// configPath or, if empty, the HANAUTIL_CONFIG environment variable.
+func resolveDSN(flags connConfig, configPath string, getenv func(string) string) (string, error) {
+	c := connConfig{}
+
+	if configPath == "" {
+		configPath = getenv(envConfig)
+	}
+	if configPath != "" {
+		fc, err := readConfigFile(configPath)
+		if err != nil {
+			return "", err
+		}
+		c.merge(fc)
+	}
+
+	ec, err := envConn(getenv)
+	if err != nil {
+		return "", err
+	}
+	c.merge(ec)
+	c.merge(flags)
+
+	return c.dsn()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantRest int
+		wantOk   bool
+	}{
+		{"Version", []string{"version"}, "version", 0, true},
+		{"SubCommand", []string{"backup", "truncate", "-days", "28"}, "backup truncate", 2, true},
+		{"NoArgs", []string{}, "", 0, false},
+		{"Unknown", []string{"backup", "delete"}, "", 0, false},
+		{"MissingSubCommand", []string{"traces"}, "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, ok := findCommand(tt.args)
+			if ok != tt.wantOk {
+				t.Fatalf("findCommand() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if got.name != tt.wantName {
+				t.Errorf("findCommand() = %v, want %v", got.name, tt.wantName)
+			}
+			if len(rest) != tt.wantRest {
+				t.Errorf("findCommand() rest = %v, want %d args", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"NoCommand", []string{}, 2},
+		{"UnknownCommand", []string{"nope"}, 2},
+		{"Help", []string{"log", "stats", "-h"}, 0},
+		{"UnknownFlag", []string{"log", "stats", "-days", "1"}, 2},
+		{"ExtraArgs", []string{"version", "extra"}, 2},
+		{"BadOutput", []string{"version", "-output", "xml"}, 2},
+		{"NoConnection", []string{"version"}, 1},
+		{"TruncateWithoutDays", []string{"backup", "truncate", "-complete"}, 2},
+		{"PurgeWithoutDays", []string{"alerts", "purge"}, 2},
+		{"TracesRemoveWithoutDays", []string{"traces", "remove", "-dry-run"}, 2},
+		{"TruncateWithDays", []string{"backup", "truncate", "-days", "0"}, 1},
+		{"SingleTraceFile", []string{"traces", "remove", "-trace-host", "hana01", "-trace-file", "old.trc"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tt.args, &stdout, &stderr, noEnv); got != tt.want {
+				t.Errorf("run() = %v, want %v, stderr %s", got, tt.want, stderr.String())
+			}
+		})
+	}
+}
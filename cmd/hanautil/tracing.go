@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+/******************************************************************************/
+/* This file sets up the optional OpenTelemetry exporter used by the CLI.     */
+/******************************************************************************/
+
+// Trace exporters
+const (
+	otelNone   = ""
+	otelStdout = "stdout"
+	otelOTLP   = "otlp"
+)
+
+// newTracerProvider returns a TracerProvider that exports spans using the
+// named exporter. Spans exported to stdout are written to 'stderr' so that
+// they do not mix with command output. The OTLP exporter sends spans over
+// HTTP and is configured with the standard OTEL_EXPORTER_OTLP_* environment
+// variables, by default sending to a collector on localhost:4318.
+func newTracerProvider(ctx context.Context, exporter string, stderr io.Writer) (*sdktrace.TracerProvider, error) {
+	var exp sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case otelStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(stderr), stdouttrace.WithPrettyPrint())
+	case otelOTLP:
+		exp, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("invalid trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewSchemaless(semconv.ServiceName("hanautil"))
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	), nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+/******************************************************************************/
+/* This file reads the daemon config, which lists the systems to maintain and */
+/* the housekeeping jobs to run on each system.                               */
+/******************************************************************************/
+
+// config is the daemon config read from a JSON file
+type config struct {
+	// HistoryFile is the file to which each run is appended as a line of JSON
+	HistoryFile string `json:"history_file"`
+	// Jitter is the maximum random delay added to each scheduled run, for
+	// example "5m", so that systems are not all maintained at once
+	Jitter duration `json:"jitter"`
+	// JobTimeout is the time after which a running job is abandoned, for
+	// example "2h". If not set defaultJobTimeout is used.
+	JobTimeout duration `json:"job_timeout"`
+	// ShutdownGrace is the time that jobs running at shutdown are waited for
+	// before they are abandoned, for example "5m". If not set
+	// defaultShutdownGrace is used.
+	ShutdownGrace duration       `json:"shutdown_grace"`
+	Systems       []systemConfig `json:"systems"`
+}
+
+// defaultJobTimeout is used when the config does not set a job timeout
+const defaultJobTimeout = time.Hour
+
+// defaultShutdownGrace is used when the config does not set a shutdown grace
+const defaultShutdownGrace = time.Minute
+
+// jobTimeout returns the time after which a running job is abandoned
+func (c *config) jobTimeout() time.Duration {
+	if c.JobTimeout == 0 {
+		return defaultJobTimeout
+	}
+	return time.Duration(c.JobTimeout)
+}
+
+// shutdownGrace returns the time that running jobs are waited for at shutdown
+func (c *config) shutdownGrace() time.Duration {
+	if c.ShutdownGrace == 0 {
+		return defaultShutdownGrace
+	}
+	return time.Duration(c.ShutdownGrace)
+}
+
+// systemConfig holds the connection details and jobs of a single system. The
+// password is read from the environment variable named by PasswordEnv so that
+// it is not held in the config file.
+type systemConfig struct {
+	Name        string      `json:"name"`
+	DSN         string      `json:"dsn"`
+	Host        string      `json:"host"`
+	Port        uint        `json:"port"`
+	User        string      `json:"user"`
+	PasswordEnv string      `json:"password_env"`
+	Jobs        []jobConfig `json:"jobs"`
+}
+
+// jobConfig is a single housekeeping task run on a cron schedule. Task is one
+// of the housekeeping step types, such as "trace_files". RetentionDays is
+// required by every task except reclaim_log, which does not use it, so that a
+// forgotten retention cannot remove everything. Complete is only used by the
+// backup_catalog task.
+type jobConfig struct {
+	Task          hanautil.HousekeepingStepType `json:"task"`
+	Schedule      string                        `json:"schedule"`
+	RetentionDays *uint                         `json:"retention_days"`
+	Complete      bool                          `json:"complete"`
+}
+
+// duration is a time.Duration read from JSON as a string such as "5m"
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// readConfig reads and validates the config file at path
+func readConfig(path string) (*config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c config
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	err = c.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// validate checks that every system can be connected to and every job has a
+// known task, a retention where one is used and a valid schedule
+func (c *config) validate() error {
+	if len(c.Systems) == 0 {
+		return fmt.Errorf("no systems configured")
+	}
+	if c.Jitter < 0 {
+		return fmt.Errorf("jitter must not be negative")
+	}
+	if c.JobTimeout < 0 {
+		return fmt.Errorf("job_timeout must not be negative")
+	}
+	if c.ShutdownGrace < 0 {
+		return fmt.Errorf("shutdown_grace must not be negative")
+	}
+	names := make(map[string]bool)
+	for _, s := range c.Systems {
+		if s.Name == "" {
+			return fmt.Errorf("system without a name")
+		}
+		if names[s.Name] {
+			return fmt.Errorf("system %s configured more than once", s.Name)
+		}
+		names[s.Name] = true
+		if s.DSN == "" && (s.Host == "" || s.Port == 0 || s.User == "") {
+			return fmt.Errorf("system %s needs a dsn or a host, port and user", s.Name)
+		}
+		for _, j := range s.Jobs {
+			switch j.Task {
+			case hanautil.StepTraceFiles, hanautil.StepBackupCatalog, hanautil.StepStatServerAlerts:
+				if j.RetentionDays == nil {
+					return fmt.Errorf("system %s task %s needs retention_days", s.Name, j.Task)
+				}
+			case hanautil.StepReclaimLog:
+			default:
+				return fmt.Errorf("system %s has an unknown task %q", s.Name, j.Task)
+			}
+			_, err := parseSchedule(j.Schedule)
+			if err != nil {
+				return fmt.Errorf("system %s task %s: %w", s.Name, j.Task, err)
+			}
+		}
+	}
+	return nil
+}
+
+// dsn returns the DSN used to connect to the system, reading the password from
+// the environment with getenv
+func (s *systemConfig) dsn(getenv func(string) string) string {
+	if s.DSN != "" {
+		return s.DSN
+	}
+	var password string
+	if s.PasswordEnv != "" {
+		password = getenv(s.PasswordEnv)
+	}
+	u := url.URL{
+		Scheme: "hdb",
+		User:   url.UserPassword(s.User, password),
+		Host:   net.JoinHostPort(s.Host, strconv.FormatUint(uint64(s.Port), 10)),
+	}
+	return u.String()
+}
+
+// policy returns the housekeeping policy that performs only the job's task
+func (j *jobConfig) policy() hanautil.HousekeepingPolicy {
+	p := hanautil.HousekeepingPolicy{}
+	var days uint
+	if j.RetentionDays != nil {
+		days = *j.RetentionDays
+	}
+	switch j.Task {
+	case hanautil.StepTraceFiles:
+		p.TraceFiles = true
+		p.TraceRetentionDays = days
+	case hanautil.StepBackupCatalog:
+		p.BackupCatalog = true
+		p.BackupRetentionDays = days
+		p.Complete = j.Complete
+	case hanautil.StepStatServerAlerts:
+		p.StatServerAlerts = true
+		p.AlertRetentionDays = days
+	case hanautil.StepReclaimLog:
+		p.ReclaimLog = true
+	}
+	return p
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"Every", "* * * * *", false},
+		{"Daily", "30 2 * * *", false},
+		{"ListsAndRanges", "0,30 8-18 * 1-6 1-5", false},
+		{"Steps", "*/15 */2 1/7 * *", false},
+		{"TooFewFields", "* * * *", true},
+		{"BadValue", "x * * * *", true},
+		{"OutOfRange", "60 * * * *", true},
+		{"BadRange", "* 5-2 * * *", true},
+		{"BadStep", "*/0 * * * *", true},
+		{"DayOfWeekSeven", "* * * * 7", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedule_next(t *testing.T) {
+	/*A Saturday*/
+	from := time.Date(2022, 1, 1, 12, 0, 30, 0, time.UTC)
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{"Every", "* * * * *", time.Date(2022, 1, 1, 12, 1, 0, 0, time.UTC)},
+		{"LaterToday", "30 14 * * *", time.Date(2022, 1, 1, 14, 30, 0, 0, time.UTC)},
+		{"Tomorrow", "0 2 * * *", time.Date(2022, 1, 2, 2, 0, 0, 0, time.UTC)},
+		{"Step", "*/15 * * * *", time.Date(2022, 1, 1, 12, 15, 0, 0, time.UTC)},
+		{"Monday", "0 3 * * 1", time.Date(2022, 1, 3, 3, 0, 0, 0, time.UTC)},
+		{"NextMonth", "0 0 1 * *", time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthOrWeek", "0 0 15 * 0", time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthStepAndWeek", "0 0 */2 * 0", time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"Never", "0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.next(from); !got.Equal(tt.want) {
+				t.Errorf("schedule.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+/******************************************************************************/
+/* This file contains the scheduler. Each job has its own goroutine that      */
+/* sleeps until the job is next due and then runs it through the housekeeping */
+/* plan and apply functions of the library.                                   */
+/******************************************************************************/
+
+// daemon runs the jobs of every configured system
+type daemon struct {
+	cfg       *config
+	newClient func(dsn string) (hanautil.Client, error)
+	getenv    func(string) string
+	history   *history
+	log       *slog.Logger
+	now       func() time.Time
+	jitter    func(max time.Duration) time.Duration
+
+	/*Only one job runs on a system at a time*/
+	locks map[string]*sync.Mutex
+}
+
+// newDaemon returns a daemon for 'cfg' that connects to systems with
+// hanautil.NewClient
+func newDaemon(cfg *config, getenv func(string) string, log *slog.Logger) *daemon {
+	d := &daemon{
+		cfg: cfg,
+		newClient: func(dsn string) (hanautil.Client, error) {
+			c := hanautil.NewClient(dsn)
+			return c, c.Connect()
+		},
+		getenv:  getenv,
+		history: &history{path: cfg.HistoryFile},
+		log:     log,
+		now:     time.Now,
+		jitter: func(max time.Duration) time.Duration {
+			if max <= 0 {
+				return 0
+			}
+			return rand.N(max)
+		},
+		locks: make(map[string]*sync.Mutex),
+	}
+	for _, s := range cfg.Systems {
+		d.locks[s.Name] = &sync.Mutex{}
+	}
+	return d
+}
+
+// run schedules every job until 'ctx' is cancelled. Jobs that are running when
+// 'ctx' is cancelled are waited for up to the shutdown grace, those still
+// running after it are abandoned and recorded as failed before run returns.
+func (d *daemon) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range d.cfg.Systems {
+		for _, j := range s.Jobs {
+			/*The config is validated when read*/
+			sched, _ := parseSchedule(j.Schedule)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.loop(ctx, s, j, sched)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// loop runs a single job each time it is due until 'ctx' is cancelled
+func (d *daemon) loop(ctx context.Context, s systemConfig, j jobConfig, sched *schedule) {
+	for {
+		next := sched.next(d.now())
+		if next.IsZero() {
+			d.log.Warn("job is never due", "system", s.Name, "task", j.Task, "schedule", j.Schedule)
+			return
+		}
+		wait := next.Sub(d.now()) + d.jitter(time.Duration(d.cfg.Jitter))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		d.runJob(ctx, s, j, next)
+	}
+}
+
+// runJob runs a single job and records it in the history. If another job is
+// running on the system, the job is skipped.
+//
+// The client's operations cannot be interrupted, so a job that exceeds the job
+// timeout, or is still running at the end of the shutdown grace that starts
+// when 'ctx' is cancelled, is abandoned and recorded as failed. It keeps the
+// system's lock until it returns, so later jobs on the system are skipped
+// rather than run alongside it.
+func (d *daemon) runJob(ctx context.Context, s systemConfig, j jobConfig, scheduled time.Time) runRecord {
+	r := runRecord{System: s.Name, Task: j.Task, Scheduled: scheduled, Started: d.now()}
+
+	lock := d.locks[s.Name]
+	if lock.TryLock() {
+		/*Buffered so that an abandoned job does not block forever*/
+		done := make(chan runRecord, 1)
+		res := r
+		go func() {
+			defer lock.Unlock()
+			d.housekeep(s, j, &res)
+			done <- res
+		}()
+
+		timer := time.NewTimer(d.cfg.jobTimeout())
+		/*Not started until shutdown*/
+		var grace <-chan time.Time
+		shutdown := ctx.Done()
+	wait:
+		for {
+			select {
+			case r = <-done:
+				break wait
+			case <-timer.C:
+				r.Status = statusFailed
+				r.Error = fmt.Sprintf("job abandoned after %s, the system is locked until it returns", d.cfg.jobTimeout())
+				break wait
+			case <-shutdown:
+				d.log.Info("waiting for job at shutdown", "system", s.Name, "task", j.Task, "grace", d.cfg.shutdownGrace())
+				shutdown = nil
+				grace = time.After(d.cfg.shutdownGrace())
+			case <-grace:
+				r.Status = statusFailed
+				r.Error = fmt.Sprintf("job abandoned at shutdown after waiting %s", d.cfg.shutdownGrace())
+				break wait
+			}
+		}
+		timer.Stop()
+	} else {
+		r.Status = statusSkipped
+		r.Error = "another job is running on the system"
+	}
+	r.Finished = d.now()
+
+	attrs := []any{"system", r.System, "task", r.Task, "status", r.Status,
+		"entries_removed", r.EntriesRemoved, "bytes_removed", r.BytesRemoved}
+	if r.Error != "" {
+		d.log.Warn("job finished", append(attrs, "error", r.Error)...)
+	} else {
+		d.log.Info("job finished", attrs...)
+	}
+	err := d.history.record(r)
+	if err != nil {
+		d.log.Error("failed to record run history", "error", err)
+	}
+	return r
+}
+
+// housekeep connects to the system, plans and applies the job's housekeeping
+// and records the result in 'r'
+func (d *daemon) housekeep(s systemConfig, j jobConfig, r *runRecord) {
+	fail := func(err error) {
+		r.Status = statusFailed
+		r.Error = err.Error()
+	}
+
+	c, err := d.newClient(s.dsn(d.getenv))
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer c.Close()
+
+	plan, err := c.PlanHousekeeping(j.policy())
+	if err != nil {
+		fail(err)
+		return
+	}
+	report, err := c.ApplyHousekeeping(plan)
+	if report != nil {
+		r.EntriesRemoved = report.EntriesRemoved()
+		r.BytesRemoved = report.BytesRemoved()
+		for _, st := range report.Steps {
+			r.Failed += st.Failed
+		}
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+	r.Status = statusSuccess
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+	"github.com/mr-stringer/hanautil/hanautiltest"
+)
+
+var genTime = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestDaemon returns a daemon for a single system "PRD" backed by 'f'
+// that records its history in a temporary file
+func newTestDaemon(t *testing.T, f *hanautiltest.Fake, jobs ...jobConfig) *daemon {
+	t.Helper()
+	cfg := &config{
+		HistoryFile: filepath.Join(t.TempDir(), "history.jsonl"),
+		Systems:     []systemConfig{{Name: "PRD", DSN: "hdb://u:p@h:30015", Jobs: jobs}},
+	}
+	d := newDaemon(cfg, func(string) string { return "" }, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	d.newClient = func(string) (hanautil.Client, error) {
+		return f, f.Connect()
+	}
+	d.now = func() time.Time { return genTime }
+	return d
+}
+
+func readHistory(t *testing.T, path string) []runRecord {
+	t.Helper()
+	fh, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	runs := make([]runRecord, 0)
+	s := bufio.NewScanner(fh)
+	for s.Scan() {
+		var r runRecord
+		err = json.Unmarshal(s.Bytes(), &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, r)
+	}
+	return runs
+}
+
+func TestDaemonRunJob(t *testing.T) {
+	f := &hanautiltest.Fake{
+		Now: genTime,
+		TraceFiles: []hanautiltest.TraceFile{
+			{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "old.trc", FileSizeBytes: 100, LastModified: genTime.AddDate(0, 0, -30)}},
+			{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "new.trc", FileSizeBytes: 300, LastModified: genTime}},
+		},
+	}
+	job := jobConfig{Task: hanautil.StepTraceFiles, Schedule: "0 1 * * *", RetentionDays: retention(7)}
+	d := newTestDaemon(t, f, job)
+
+	r := d.runJob(context.Background(), d.cfg.Systems[0], job, genTime)
+	if r.Status != statusSuccess || r.Error != "" {
+		t.Fatalf("runJob() status = %s, error = %s", r.Status, r.Error)
+	}
+	if r.EntriesRemoved != 1 || r.BytesRemoved != 100 {
+		t.Errorf("runJob() removed %d entries and %d bytes, want 1 and 100", r.EntriesRemoved, r.BytesRemoved)
+	}
+	if len(f.TraceFiles) != 1 {
+		t.Errorf("%d trace files left, want 1", len(f.TraceFiles))
+	}
+
+	/*A failed connection is recorded*/
+	f.Errors = map[string]error{"Connect": errors.New("ConnectionRefused")}
+	r = d.runJob(context.Background(), d.cfg.Systems[0], job, genTime)
+	if r.Status != statusFailed || r.Error != "ConnectionRefused" {
+		t.Errorf("runJob() status = %s, error = %s", r.Status, r.Error)
+	}
+
+	runs := readHistory(t, d.cfg.HistoryFile)
+	if len(runs) != 2 {
+		t.Fatalf("history holds %d runs, want 2", len(runs))
+	}
+	if runs[0].System != "PRD" || runs[0].Task != hanautil.StepTraceFiles || runs[0].Status != statusSuccess || !runs[0].Scheduled.Equal(genTime) {
+		t.Errorf("unexpected first run %+v", runs[0])
+	}
+	if runs[1].Status != statusFailed {
+		t.Errorf("second run status = %s, want %s", runs[1].Status, statusFailed)
+	}
+}
+
+func TestDaemonRunJobOverlap(t *testing.T) {
+	f := &hanautiltest.Fake{Now: genTime}
+	job := jobConfig{Task: hanautil.StepReclaimLog, Schedule: "* * * * *"}
+	d := newTestDaemon(t, f, job)
+
+	/*Another job holds the system*/
+	d.locks["PRD"].Lock()
+	r := d.runJob(context.Background(), d.cfg.Systems[0], job, genTime)
+	d.locks["PRD"].Unlock()
+	if r.Status != statusSkipped {
+		t.Errorf("runJob() status = %s, want %s", r.Status, statusSkipped)
+	}
+
+	r = d.runJob(context.Background(), d.cfg.Systems[0], job, genTime)
+	if r.Status != statusSuccess {
+		t.Errorf("runJob() status = %s, error = %s", r.Status, r.Error)
+	}
+}
+
+func TestDaemonRunShutdown(t *testing.T) {
+	f := &hanautiltest.Fake{Now: genTime}
+	job := jobConfig{Task: hanautil.StepReclaimLog, Schedule: "0 1 * * *"}
+	d := newTestDaemon(t, f, job)
+	d.now = time.Now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.run(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run() did not return after the context was cancelled")
+	}
+}
+
+// blockingClient is a client whose housekeeping plan does not return until
+// release is closed
+type blockingClient struct {
+	*hanautiltest.Fake
+	release chan struct{}
+}
+
+func (c *blockingClient) PlanHousekeeping(p hanautil.HousekeepingPolicy) (*hanautil.HousekeepingPlan, error) {
+	<-c.release
+	return c.Fake.PlanHousekeeping(p)
+}
+
+func TestDaemonRunJobTimeout(t *testing.T) {
+	c := &blockingClient{Fake: &hanautiltest.Fake{Now: genTime}, release: make(chan struct{})}
+	job := jobConfig{Task: hanautil.StepReclaimLog, Schedule: "* * * * *"}
+	d := newTestDaemon(t, c.Fake, job)
+	d.newClient = func(string) (hanautil.Client, error) {
+		return c, nil
+	}
+	d.cfg.JobTimeout = duration(10 * time.Millisecond)
+
+	r := d.runJob(context.Background(), d.cfg.Systems[0], job, genTime)
+	if r.Status != statusFailed || !strings.HasPrefix(r.Error, "job abandoned after") {
+		t.Errorf("runJob() status = %s, error = %s", r.Status, r.Error)
+	}
+
+	/*The abandoned job still holds the system*/
+	r = d.runJob(context.Background(), d.cfg.Systems[0], job, genTime)
+	if r.Status != statusSkipped {
+		t.Errorf("runJob() status = %s, want %s", r.Status, statusSkipped)
+	}
+
+	close(c.release)
+
+	/*Shutdown abandons a job still running after the grace*/
+	c2 := &blockingClient{Fake: &hanautiltest.Fake{Now: genTime}, release: make(chan struct{})}
+	defer close(c2.release)
+	d = newTestDaemon(t, c2.Fake, job)
+	d.newClient = func(string) (hanautil.Client, error) {
+		return c2, nil
+	}
+	d.cfg.ShutdownGrace = duration(10 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = d.runJob(ctx, d.cfg.Systems[0], job, genTime)
+	if r.Status != statusFailed || !strings.HasPrefix(r.Error, "job abandoned at shutdown") {
+		t.Errorf("runJob() status = %s, error = %s", r.Status, r.Error)
+	}
+
+	/*A job that finishes within the grace is recorded as it finished*/
+	c3 := &blockingClient{Fake: &hanautiltest.Fake{Now: genTime}, release: make(chan struct{})}
+	d = newTestDaemon(t, c3.Fake, job)
+	d.newClient = func(string) (hanautil.Client, error) {
+		return c3, nil
+	}
+	d.cfg.ShutdownGrace = duration(time.Minute)
+	time.AfterFunc(10*time.Millisecond, func() { close(c3.release) })
+	r = d.runJob(ctx, d.cfg.Systems[0], job, genTime)
+	if r.Status != statusSuccess {
+		t.Errorf("runJob() status = %s, error = %s, want %s", r.Status, r.Error, statusSuccess)
+	}
+}
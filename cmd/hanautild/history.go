@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+/******************************************************************************/
+/* This file persists the run history. Each run is appended to the history    */
+/* file as a single line of JSON so that the file survives restarts and can   */
+/* be read with standard tools.                                               */
+/******************************************************************************/
+
+// Run statuses
+const (
+	statusSuccess = "success"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// runRecord is the record of a single scheduled job
+type runRecord struct {
+	System         string                        `json:"system"`
+	Task           hanautil.HousekeepingStepType `json:"task"`
+	Scheduled      time.Time                     `json:"scheduled"`
+	Started        time.Time                     `json:"started"`
+	Finished       time.Time                     `json:"finished"`
+	Status         string                        `json:"status"`
+	EntriesRemoved uint64                        `json:"entries_removed"`
+	BytesRemoved   uint64                        `json:"bytes_removed"`
+	Failed         uint64                        `json:"failed"`
+	Error          string                        `json:"error,omitempty"`
+}
+
+// history appends runs to a file, if path is empty runs are not persisted
+type history struct {
+	mu   sync.Mutex
+	path string
+}
+
+// record appends 'r' to the history file
+func (h *history) record(r runRecord) error {
+	if h.path == "" {
+		return nil
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
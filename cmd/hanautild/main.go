@@ -0,0 +1,87 @@
+// Command hanautild is a long running service that performs scheduled
+// housekeeping of SAP HANA databases using the hanautil library.
+//
+// Usage:
+//
+//	hanautild -config hanautild.json
+//
+// The config file lists the systems to maintain and, for each system, the
+// housekeeping tasks to run on cron schedules:
+//
+//	{
+//	  "history_file": "/var/lib/hanautild/history.jsonl",
+//	  "jitter": "5m",
+//	  "job_timeout": "1h",
+//	  "shutdown_grace": "1m",
+//	  "systems": [{
+//	    "name": "PRD",
+//	    "host": "hana01", "port": 30015, "user": "HOUSEKEEPER",
+//	    "password_env": "PRD_PASSWORD",
+//	    "jobs": [
+//	      {"task": "trace_files", "schedule": "0 1 * * *", "retention_days": 30},
+//	      {"task": "backup_catalog", "schedule": "0 2 * * 0", "retention_days": 28},
+//	      {"task": "stat_server_alerts", "schedule": "0 3 * * *", "retention_days": 42},
+//	      {"task": "reclaim_log", "schedule": "30 3 * * *"}
+//	    ]
+//	  }]
+//	}
+//
+// Schedules use the five standard cron fields and are evaluated in local time.
+// Each run is delayed by a random amount up to the jitter. Only one job runs
+// on a system at a time, a job that is due while another is running is
+// skipped. Each run is appended to the history file as a line of JSON.
+//
+// Every task except reclaim_log must set retention_days. A job that runs for
+// longer than job_timeout, one hour if not set, is recorded as failed and
+// abandoned. The database call it is waiting on cannot be interrupted, so the
+// system stays locked, and its later jobs are skipped, until the call returns.
+//
+// On SIGINT or SIGTERM no further jobs are started and running jobs are waited
+// for up to shutdown_grace, one minute if not set. Jobs still running after it
+// are recorded as failed and hanautild exits without waiting for their
+// database calls.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr, os.Getenv))
+}
+
+func run(args []string, stderr io.Writer, getenv func(string) string) int {
+	fs := flag.NewFlagSet("hanautild", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "path to the JSON config file")
+	err := fs.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if *configPath == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := readConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	log := slog.New(slog.NewTextHandler(stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("hanautild started", "systems", len(cfg.Systems))
+	newDaemon(cfg, getenv, log).run(ctx)
+	log.Info("hanautild stopped")
+	return 0
+}
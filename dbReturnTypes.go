@@ -1,58 +1,561 @@
 package hanautil
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // TraceFile is a struct that contains information about a HANA trace file
 type TraceFile struct {
-	Hostname      string
-	FileName      string
-	FileSizeBytes uint64
-	LastModified  time.Time
+	Hostname      string    `json:"hostname" yaml:"hostname"`
+	FileName      string    `json:"file_name" yaml:"file_name"`
+	FileSizeBytes uint64    `json:"file_size_bytes" yaml:"file_size_bytes"`
+	LastModified  time.Time `json:"last_modified" yaml:"last_modified"`
 }
 
-// BackupSummary is a struct that contains information regarding HANA backups
+// BackupSummary is a struct that contains information regarding HANA backups.
+// The dates of each type of backup are given in UTC and CurrentDbTime is the
+// database time in UTC when the summary was made. OldestFullBackupDate and
+// OldestLogBackupDate repeat the oldest dates of FullBackupDates and
+// LogBackupDates.
+//
+// Types holds the statistics of every entry type found in the catalog, keyed
+// by the name of the type. It includes types without fields of their own,
+// such as those added by newer releases of HANA.
 type BackupSummary struct {
-	BackupCatalogEntries      uint64
-	FullBackups               uint64
-	LogBackups                uint64
-	IncrementalBackups        uint64
-	DifferentialBackups       uint64
-	LogMissing                uint64
-	DataSnapshots             uint64
-	SizeOfFullBackupsBytes    uint64
-	SizeOfLogBackupBytes      uint64
-	SizeOfIncrementalBackups  uint64
-	SizeOfDifferentialBackups uint64
-	SizeOfLogMissing          uint64
-	SizeOfDataSnapshots       uint64
-	SizeOfBackupCatalog       uint64
-	OldestFullBackupDate      time.Time
-	OldestLogBackupDate       time.Time
-	CurrentDbTime             time.Time
+	BackupCatalogEntries      uint64                     `json:"backup_catalog_entries" yaml:"backup_catalog_entries"`
+	FullBackups               uint64                     `json:"full_backups" yaml:"full_backups"`
+	LogBackups                uint64                     `json:"log_backups" yaml:"log_backups"`
+	IncrementalBackups        uint64                     `json:"incremental_backups" yaml:"incremental_backups"`
+	DifferentialBackups       uint64                     `json:"differential_backups" yaml:"differential_backups"`
+	LogMissing                uint64                     `json:"log_missing" yaml:"log_missing"`
+	DataSnapshots             uint64                     `json:"data_snapshots" yaml:"data_snapshots"`
+	SizeOfFullBackupsBytes    uint64                     `json:"full_backups_bytes" yaml:"full_backups_bytes"`
+	SizeOfLogBackupBytes      uint64                     `json:"log_backups_bytes" yaml:"log_backups_bytes"`
+	SizeOfIncrementalBackups  uint64                     `json:"incremental_backups_bytes" yaml:"incremental_backups_bytes"`
+	SizeOfDifferentialBackups uint64                     `json:"differential_backups_bytes" yaml:"differential_backups_bytes"`
+	SizeOfLogMissing          uint64                     `json:"log_missing_bytes" yaml:"log_missing_bytes"`
+	SizeOfDataSnapshots       uint64                     `json:"data_snapshots_bytes" yaml:"data_snapshots_bytes"`
+	SizeOfBackupCatalog       uint64                     `json:"backup_catalog_bytes" yaml:"backup_catalog_bytes"`
+	OldestFullBackupDate      time.Time                  `json:"oldest_full_backup_date" yaml:"oldest_full_backup_date"`
+	OldestLogBackupDate       time.Time                  `json:"oldest_log_backup_date" yaml:"oldest_log_backup_date"`
+	CurrentDbTime             time.Time                  `json:"current_db_time" yaml:"current_db_time"`
+	FullBackupDates           BackupDates                `json:"full_backup_dates" yaml:"full_backup_dates"`
+	LogBackupDates            BackupDates                `json:"log_backup_dates" yaml:"log_backup_dates"`
+	IncrementalBackupDates    BackupDates                `json:"incremental_backup_dates" yaml:"incremental_backup_dates"`
+	DifferentialBackupDates   BackupDates                `json:"differential_backup_dates" yaml:"differential_backup_dates"`
+	DataSnapshotDates         BackupDates                `json:"data_snapshot_dates" yaml:"data_snapshot_dates"`
+	Types                     map[string]BackupTypeStats `json:"types" yaml:"types"`
 }
 
+// Backup catalog entry types, as named in the ENTRY_TYPE_NAME column of the
+// backup catalog. Each has its own fields in BackupSummary.
+const (
+	EntryTypeFullBackup         = "complete data backup"
+	EntryTypeLogBackup          = "log backup"
+	EntryTypeIncrementalBackup  = "incremental data backup"
+	EntryTypeDifferentialBackup = "differential data backup"
+	EntryTypeLogMissing         = "log missing"
+	EntryTypeDataSnapshot       = "data snapshot"
+)
+
+// knownEntryType returns true if 'name' is one of the entry types with its own
+// fields in BackupSummary
+func knownEntryType(name string) bool {
+	switch name {
+	case EntryTypeFullBackup, EntryTypeLogBackup, EntryTypeIncrementalBackup,
+		EntryTypeDifferentialBackup, EntryTypeLogMissing, EntryTypeDataSnapshot:
+		return true
+	}
+	return false
+}
+
+// BackupTypeStats provides the number, total size and dates of the backup
+// catalog entries of a single entry type
+type BackupTypeStats struct {
+	Count     uint64      `json:"count" yaml:"count"`
+	SizeBytes uint64      `json:"size_bytes" yaml:"size_bytes"`
+	Dates     BackupDates `json:"dates" yaml:"dates"`
+}
+
+// BackupDates provides the start times, in UTC, of the oldest and newest
+// backups of a single type along with their ages in seconds relative to the
+// CurrentDbTime of the summary. Times are the zero time and ages are 0 when
+// there are no backups of the type.
+type BackupDates struct {
+	Oldest           time.Time `json:"oldest" yaml:"oldest"`
+	Newest           time.Time `json:"newest" yaml:"newest"`
+	OldestAgeSeconds int64     `json:"oldest_age_seconds" yaml:"oldest_age_seconds"`
+	NewestAgeSeconds int64     `json:"newest_age_seconds" yaml:"newest_age_seconds"`
+}
+
+// NewBackupDates returns the dates of the backups of a type started between
+// 'oldest' and 'newest' with their ages relative to 'now'
+func NewBackupDates(oldest, newest, now time.Time) BackupDates {
+	bd := BackupDates{Oldest: oldest, Newest: newest}
+	if !oldest.IsZero() {
+		bd.OldestAgeSeconds = int64(now.Sub(oldest) / time.Second)
+	}
+	if !newest.IsZero() {
+		bd.NewestAgeSeconds = int64(now.Sub(newest) / time.Second)
+	}
+	return bd
+}
+
+// BackupFilter is used to restrict the backup catalog entries described by
+// GetBackupSummaryFiltered. BeforeBackupID, if set, only includes entries with
+// a lower backup ID. From and To restrict entries to those started, in UTC, in
+// the time range [From, To), zero times are not filtered upon. The zero value
+// includes the whole catalog.
+type BackupFilter struct {
+	BeforeBackupID string    `json:"before_backup_id" yaml:"before_backup_id"`
+	From           time.Time `json:"from" yaml:"from"`
+	To             time.Time `json:"to" yaml:"to"`
+}
+
+// GetAllBytes returns the total size of the backups in the summary, including
+// the backup catalog and entry types without fields of their own
 func (bs *BackupSummary) GetAllBytes() uint64 {
-	return bs.SizeOfFullBackupsBytes +
+	total := bs.SizeOfFullBackupsBytes +
 		bs.SizeOfLogBackupBytes +
 		bs.SizeOfIncrementalBackups +
 		bs.SizeOfDifferentialBackups +
 		bs.SizeOfLogMissing +
 		bs.SizeOfDataSnapshots +
 		bs.SizeOfBackupCatalog
+	for name, t := range bs.Types {
+		if !knownEntryType(name) {
+			total += t.SizeBytes
+		}
+	}
+	return total
+}
+
+// OtherTypes returns the sorted names of the entry types in Types that have no
+// fields of their own in BackupSummary
+func (bs *BackupSummary) OtherTypes() []string {
+	names := make([]string, 0)
+	for name := range bs.Types {
+		if !knownEntryType(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackupSummary returns the summary of backup catalog entries whose
+// statistics by entry type are 'types'. Only the oldest and newest dates of
+// each type need be set, their ages are set relative to 'now'. 'catalogBytes'
+// is the size of the backup catalog. It allows implementations of Client, such
+// as fakes, to build a summary in the same way as HanaUtilClient.
+func NewBackupSummary(types map[string]BackupTypeStats, catalogBytes uint64, now time.Time) *BackupSummary {
+	bs := BackupSummary{
+		Types:               make(map[string]BackupTypeStats, len(types)),
+		SizeOfBackupCatalog: catalogBytes,
+		CurrentDbTime:       now,
+	}
+	for name, t := range types {
+		bs.Types[name] = t
+		bs.BackupCatalogEntries += t.Count
+	}
+	bs.setTypedFields()
+	return &bs
+}
+
+// setTypedFields sets the ages of the dates in Types relative to CurrentDbTime
+// and copies the statistics of each known entry type to its own fields
+func (bs *BackupSummary) setTypedFields() {
+	for name, t := range bs.Types {
+		t.Dates = NewBackupDates(t.Dates.Oldest, t.Dates.Newest, bs.CurrentDbTime)
+		bs.Types[name] = t
+	}
+
+	full := bs.Types[EntryTypeFullBackup]
+	bs.FullBackups, bs.SizeOfFullBackupsBytes, bs.FullBackupDates = full.Count, full.SizeBytes, full.Dates
+	bs.OldestFullBackupDate = full.Dates.Oldest
+	log := bs.Types[EntryTypeLogBackup]
+	bs.LogBackups, bs.SizeOfLogBackupBytes, bs.LogBackupDates = log.Count, log.SizeBytes, log.Dates
+	bs.OldestLogBackupDate = log.Dates.Oldest
+	inc := bs.Types[EntryTypeIncrementalBackup]
+	bs.IncrementalBackups, bs.SizeOfIncrementalBackups, bs.IncrementalBackupDates = inc.Count, inc.SizeBytes, inc.Dates
+	diff := bs.Types[EntryTypeDifferentialBackup]
+	bs.DifferentialBackups, bs.SizeOfDifferentialBackups, bs.DifferentialBackupDates = diff.Count, diff.SizeBytes, diff.Dates
+	missing := bs.Types[EntryTypeLogMissing]
+	bs.LogMissing, bs.SizeOfLogMissing = missing.Count, missing.SizeBytes
+	snap := bs.Types[EntryTypeDataSnapshot]
+	bs.DataSnapshots, bs.SizeOfDataSnapshots, bs.DataSnapshotDates = snap.Count, snap.SizeBytes, snap.Dates
+}
+
+// LatestBackups provides the start times of the newest successful full and log
+// backups in the backup catalog, in UTC. A time is the zero time if no such
+// backup exists. CurrentDbTime is the database time in UTC when the catalog
+// was read.
+type LatestBackups struct {
+	FullBackup    time.Time `json:"full_backup" yaml:"full_backup"`
+	LogBackup     time.Time `json:"log_backup" yaml:"log_backup"`
+	CurrentDbTime time.Time `json:"current_db_time" yaml:"current_db_time"`
+}
+
+// BackupFileStats provides the number and size of a set of backup files of
+// the backup catalog
+type BackupFileStats struct {
+	Files uint64 `json:"files" yaml:"files"`
+	Bytes uint64 `json:"bytes" yaml:"bytes"`
 }
 
 // TruncateStats provided information regarding the number of files and the
 // amount of data removed by truncating the backup catalog
 type TruncateStats struct {
-	FilesRemoved uint64
-	BytesRemoved uint64
+	FilesRemoved uint64 `json:"files_removed" yaml:"files_removed"`
+	BytesRemoved uint64 `json:"bytes_removed" yaml:"bytes_removed"`
 }
 
 // LogSegmentsStats provides information about how much space is used by
 // freeable and non-freeable log segments in the log volume
 type LogSegmentsStats struct {
-	FreeSegments             uint64
-	TotalFreeSegmentBytes    uint64
-	NonFreeSegments          uint64
-	TotalNonFreeSegmentBytes uint64
+	FreeSegments             uint64 `json:"free_segments" yaml:"free_segments"`
+	TotalFreeSegmentBytes    uint64 `json:"free_segment_bytes" yaml:"free_segment_bytes"`
+	NonFreeSegments          uint64 `json:"non_free_segments" yaml:"non_free_segments"`
+	TotalNonFreeSegmentBytes uint64 `json:"non_free_segment_bytes" yaml:"non_free_segment_bytes"`
+}
+
+// ServiceLogSegments provides the number and size of free log segments held by
+// a single HANA service, identified by its host and port
+type ServiceLogSegments struct {
+	Hostname         string `json:"hostname" yaml:"hostname"`
+	Port             uint32 `json:"port" yaml:"port"`
+	ServiceName      string `json:"service_name" yaml:"service_name"`
+	FreeSegments     uint64 `json:"free_segments" yaml:"free_segments"`
+	FreeSegmentBytes uint64 `json:"free_segment_bytes" yaml:"free_segment_bytes"`
+}
+
+// ServiceLogReclaim provides the free log segments of a single HANA service
+// before and after a log reclaim
+type ServiceLogReclaim struct {
+	Hostname               string `json:"hostname" yaml:"hostname"`
+	Port                   uint32 `json:"port" yaml:"port"`
+	ServiceName            string `json:"service_name" yaml:"service_name"`
+	FreeSegmentsBefore     uint64 `json:"free_segments_before" yaml:"free_segments_before"`
+	FreeSegmentBytesBefore uint64 `json:"free_segment_bytes_before" yaml:"free_segment_bytes_before"`
+	FreeSegmentsAfter      uint64 `json:"free_segments_after" yaml:"free_segments_after"`
+	FreeSegmentBytesAfter  uint64 `json:"free_segment_bytes_after" yaml:"free_segment_bytes_after"`
+}
+
+// BytesReclaimed returns the number of bytes freed for the service. If the
+// service has more free log after the reclaim than before, 0 is returned.
+func (sr *ServiceLogReclaim) BytesReclaimed() uint64 {
+	if sr.FreeSegmentBytesAfter > sr.FreeSegmentBytesBefore {
+		return 0
+	}
+	return sr.FreeSegmentBytesBefore - sr.FreeSegmentBytesAfter
+}
+
+// SegmentsReclaimed returns the number of log segments freed for the service.
+// If the service has more free segments after the reclaim than before, 0 is
+// returned.
+func (sr *ServiceLogReclaim) SegmentsReclaimed() uint64 {
+	if sr.FreeSegmentsAfter > sr.FreeSegmentsBefore {
+		return 0
+	}
+	return sr.FreeSegmentsBefore - sr.FreeSegmentsAfter
+}
+
+// ReclaimResult provides information about a log reclaim on a per service
+// basis. BeforeTime and AfterTime are the database times at which the free log
+// segments were measured.
+type ReclaimResult struct {
+	Services   []ServiceLogReclaim `json:"services" yaml:"services"`
+	BeforeTime time.Time           `json:"before_time" yaml:"before_time"`
+	AfterTime  time.Time           `json:"after_time" yaml:"after_time"`
+}
+
+// BytesReclaimed returns the total number of bytes freed across all services.
+// Each service is calculated independently so that segments becoming free in
+// one service during the reclaim do not hide the space freed in another.
+func (rr *ReclaimResult) BytesReclaimed() uint64 {
+	var total uint64
+	for i := range rr.Services {
+		total += rr.Services[i].BytesReclaimed()
+	}
+	return total
+}
+
+// SegmentsReclaimed returns the total number of log segments freed across all
+// services.
+func (rr *ReclaimResult) SegmentsReclaimed() uint64 {
+	var total uint64
+	for i := range rr.Services {
+		total += rr.Services[i].SegmentsReclaimed()
+	}
+	return total
+}
+
+// DiskUsage provides information about the size and usage of a disk used by
+// HANA. UsageType is one of DATA, LOG, TRACE, DATA_BACKUP or LOG_BACKUP.
+// UsedBytes is the space used on the file system by all processes, whereas
+// HanaUsedBytes is the space used by HANA alone.
+type DiskUsage struct {
+	Hostname       string `json:"hostname" yaml:"hostname"`
+	Path           string `json:"path" yaml:"path"`
+	UsageType      string `json:"usage_type" yaml:"usage_type"`
+	FilesystemType string `json:"filesystem_type" yaml:"filesystem_type"`
+	TotalBytes     uint64 `json:"total_bytes" yaml:"total_bytes"`
+	UsedBytes      uint64 `json:"used_bytes" yaml:"used_bytes"`
+	FreeBytes      uint64 `json:"free_bytes" yaml:"free_bytes"`
+	HanaUsedBytes  uint64 `json:"hana_used_bytes" yaml:"hana_used_bytes"`
+}
+
+// VolumeUsage provides information about the size and usage of a single data
+// or log volume file
+type VolumeUsage struct {
+	Hostname   string `json:"hostname" yaml:"hostname"`
+	Port       uint32 `json:"port" yaml:"port"`
+	VolumeID   uint32 `json:"volume_id" yaml:"volume_id"`
+	FileType   string `json:"file_type" yaml:"file_type"`
+	FileName   string `json:"file_name" yaml:"file_name"`
+	TotalBytes uint64 `json:"total_bytes" yaml:"total_bytes"`
+	UsedBytes  uint64 `json:"used_bytes" yaml:"used_bytes"`
+	FreeBytes  uint64 `json:"free_bytes" yaml:"free_bytes"`
+}
+
+// HostMemory provides information about the memory used by the HANA instance
+// on a single host
+type HostMemory struct {
+	Hostname             string `json:"hostname" yaml:"hostname"`
+	UsedBytes            uint64 `json:"used_bytes" yaml:"used_bytes"`
+	PeakUsedBytes        uint64 `json:"peak_used_bytes" yaml:"peak_used_bytes"`
+	AllocatedBytes       uint64 `json:"allocated_bytes" yaml:"allocated_bytes"`
+	AllocationLimitBytes uint64 `json:"allocation_limit_bytes" yaml:"allocation_limit_bytes"`
+}
+
+// ServiceMemory provides information about the memory used by a single HANA
+// service. PeakUsedBytes is the highest used memory recorded in the load
+// history of the service.
+type ServiceMemory struct {
+	Hostname                      string `json:"hostname" yaml:"hostname"`
+	Port                          uint32 `json:"port" yaml:"port"`
+	ServiceName                   string `json:"service_name" yaml:"service_name"`
+	UsedBytes                     uint64 `json:"used_bytes" yaml:"used_bytes"`
+	PeakUsedBytes                 uint64 `json:"peak_used_bytes" yaml:"peak_used_bytes"`
+	AllocationLimitBytes          uint64 `json:"allocation_limit_bytes" yaml:"allocation_limit_bytes"`
+	EffectiveAllocationLimitBytes uint64 `json:"effective_allocation_limit_bytes" yaml:"effective_allocation_limit_bytes"`
+}
+
+// HeapAllocator provides information about the memory used by a single heap
+// allocator of a HANA service
+type HeapAllocator struct {
+	Hostname   string `json:"hostname" yaml:"hostname"`
+	Port       uint32 `json:"port" yaml:"port"`
+	Category   string `json:"category" yaml:"category"`
+	InUseBytes uint64 `json:"in_use_bytes" yaml:"in_use_bytes"`
+}
+
+// MemoryUsage provides information about memory usage on a per host and per
+// service basis along with the largest heap allocators
+type MemoryUsage struct {
+	Hosts             []HostMemory    `json:"hosts" yaml:"hosts"`
+	Services          []ServiceMemory `json:"services" yaml:"services"`
+	TopHeapAllocators []HeapAllocator `json:"top_heap_allocators" yaml:"top_heap_allocators"`
+}
+
+// TableSizeFilter is used to restrict the tables returned by GetTableSizes.
+// An empty SchemaName includes all schemas. StoreType may be "COLUMN", "ROW"
+// or empty for both. Limit sets the maximum number of tables returned, where 0
+// returns all tables.
+type TableSizeFilter struct {
+	SchemaName string `json:"schema_name" yaml:"schema_name"`
+	StoreType  string `json:"store_type" yaml:"store_type"`
+	Limit      uint   `json:"limit" yaml:"limit"`
+}
+
+// TableSize provides information about the size of a column or row store
+// table. Sizes of partitioned tables are the total of all partitions.
+type TableSize struct {
+	SchemaName      string `json:"schema_name" yaml:"schema_name"`
+	TableName       string `json:"table_name" yaml:"table_name"`
+	StoreType       string `json:"store_type" yaml:"store_type"`
+	MemorySizeBytes uint64 `json:"memory_size_bytes" yaml:"memory_size_bytes"`
+	DiskSizeBytes   uint64 `json:"disk_size_bytes" yaml:"disk_size_bytes"`
+	RecordCount     uint64 `json:"record_count" yaml:"record_count"`
+	DeltaSizeBytes  uint64 `json:"delta_size_bytes" yaml:"delta_size_bytes"`
+	Partitions      uint64 `json:"partitions" yaml:"partitions"`
+}
+
+// TableUnload provides information about a column store table or column that
+// has been unloaded from memory
+type TableUnload struct {
+	UnloadTime time.Time `json:"unload_time" yaml:"unload_time"`
+	Hostname   string    `json:"hostname" yaml:"hostname"`
+	Port       uint32    `json:"port" yaml:"port"`
+	SchemaName string    `json:"schema_name" yaml:"schema_name"`
+	TableName  string    `json:"table_name" yaml:"table_name"`
+	ColumnName string    `json:"column_name" yaml:"column_name"`
+	Reason     string    `json:"reason" yaml:"reason"`
+}
+
+// DeltaMergeThresholds sets the minimum delta size and record count for a
+// table to be considered a delta merge candidate. A table must meet both
+// thresholds, a threshold of 0 is always met.
+type DeltaMergeThresholds struct {
+	MinDeltaBytes   uint64 `json:"min_delta_bytes" yaml:"min_delta_bytes"`
+	MinDeltaRecords uint64 `json:"min_delta_records" yaml:"min_delta_records"`
+}
+
+// DeltaMergeCandidate provides information about a column store table whose
+// delta storage exceeds the requested thresholds. LastMergeTime is the start
+// time of the last successful merge and is the zero time if no successful
+// merge is recorded.
+type DeltaMergeCandidate struct {
+	SchemaName       string    `json:"schema_name" yaml:"schema_name"`
+	TableName        string    `json:"table_name" yaml:"table_name"`
+	DeltaSizeBytes   uint64    `json:"delta_size_bytes" yaml:"delta_size_bytes"`
+	DeltaRecordCount uint64    `json:"delta_record_count" yaml:"delta_record_count"`
+	MainSizeBytes    uint64    `json:"main_size_bytes" yaml:"main_size_bytes"`
+	LastMergeTime    time.Time `json:"last_merge_time" yaml:"last_merge_time"`
+	FailedMerges     uint64    `json:"failed_merges" yaml:"failed_merges"`
+}
+
+// MergeStats provides information regarding the number of records and the
+// amount of delta storage merged into main by a delta merge
+type MergeStats struct {
+	RecordsMerged uint64 `json:"records_merged" yaml:"records_merged"`
+	BytesMerged   uint64 `json:"bytes_merged" yaml:"bytes_merged"`
+}
+
+// StatServerAlertFilter is used to restrict the alerts returned by
+// ListStatServerAlerts and SummariseStatServerAlerts. MinRating is the lowest
+// alert rating returned (1 information to 5 high), 0 returns all ratings. From
+// and To restrict alerts to the time range [From, To), zero times are not
+// filtered upon. They are converted to the local time of the database, in
+// which alerts are stored. Limit sets the maximum number of alerts returned by
+// ListStatServerAlerts, 0 returns all alerts.
+type StatServerAlertFilter struct {
+	MinRating uint      `json:"min_rating" yaml:"min_rating"`
+	From      time.Time `json:"from" yaml:"from"`
+	To        time.Time `json:"to" yaml:"to"`
+	Limit     uint      `json:"limit" yaml:"limit"`
+}
+
+// StatServerAlert provides information about a single alert raised by the
+// statistics server. Timestamp is the local time of the database, as stored by
+// the statistics server.
+type StatServerAlert struct {
+	AlertID    uint      `json:"alert_id" yaml:"alert_id"`
+	AlertName  string    `json:"alert_name" yaml:"alert_name"`
+	Rating     uint      `json:"rating" yaml:"rating"`
+	Hostname   string    `json:"hostname" yaml:"hostname"`
+	Port       uint32    `json:"port" yaml:"port"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	Details    string    `json:"details" yaml:"details"`
+	UserAction string    `json:"user_action" yaml:"user_action"`
+}
+
+// StatServerAlertSummary provides the number of times a statistics server
+// alert has been raised along with its highest rating and the first and last
+// time it was raised
+type StatServerAlertSummary struct {
+	AlertID        uint      `json:"alert_id" yaml:"alert_id"`
+	AlertName      string    `json:"alert_name" yaml:"alert_name"`
+	Count          uint64    `json:"count" yaml:"count"`
+	MaxRating      uint      `json:"max_rating" yaml:"max_rating"`
+	FirstTimestamp time.Time `json:"first_timestamp" yaml:"first_timestamp"`
+	LastTimestamp  time.Time `json:"last_timestamp" yaml:"last_timestamp"`
+}
+
+// BatchOptions controls how a batched deletion is performed. BatchSize is the
+// maximum number of rows deleted and committed in each batch, if 0 the
+// DefaultBatchSize is used. If Progress is not nil, it is called after each
+// batch is committed.
+type BatchOptions struct {
+	BatchSize uint                `json:"batch_size" yaml:"batch_size"`
+	Progress  func(BatchProgress) `json:"-" yaml:"-"`
+}
+
+// DefaultBatchSize is the number of rows deleted in each batch when the
+// BatchSize of BatchOptions is not set
+const DefaultBatchSize uint = 10000
+
+// BatchProgress provides information about a single committed batch of a
+// batched deletion
+type BatchProgress struct {
+	Batch        uint   `json:"batch" yaml:"batch"`
+	RowsRemoved  uint64 `json:"rows_removed" yaml:"rows_removed"`
+	TotalRemoved uint64 `json:"total_removed" yaml:"total_removed"`
+}
+
+// BatchDeleteStats provides information about the rows removed by a batched
+// deletion. RowsPerBatch holds the number of rows removed by each committed
+// batch in order.
+type BatchDeleteStats struct {
+	RowsPerBatch []uint64 `json:"rows_per_batch" yaml:"rows_per_batch"`
+	TotalRemoved uint64   `json:"total_removed" yaml:"total_removed"`
+}
+
+// StatisticsHistoryTable provides the number of rows and size of a statistics
+// server collector history table in the _SYS_STATISTICS schema
+type StatisticsHistoryTable struct {
+	TableName      string `json:"table_name" yaml:"table_name"`
+	RecordCount    uint64 `json:"record_count" yaml:"record_count"`
+	TableSizeBytes uint64 `json:"table_size_bytes" yaml:"table_size_bytes"`
+}
+
+// StatisticsHistoryPurge provides the number of rows and size of a statistics
+// server collector history table before and after a purge
+type StatisticsHistoryPurge struct {
+	TableName   string `json:"table_name" yaml:"table_name"`
+	RowsBefore  uint64 `json:"rows_before" yaml:"rows_before"`
+	RowsAfter   uint64 `json:"rows_after" yaml:"rows_after"`
+	BytesBefore uint64 `json:"bytes_before" yaml:"bytes_before"`
+	BytesAfter  uint64 `json:"bytes_after" yaml:"bytes_after"`
+}
+
+// RowsRemoved returns the number of rows removed from the table. If the table
+// has more rows after the purge than before, 0 is returned.
+func (sp *StatisticsHistoryPurge) RowsRemoved() uint64 {
+	if sp.RowsAfter > sp.RowsBefore {
+		return 0
+	}
+	return sp.RowsBefore - sp.RowsAfter
+}
+
+// BytesRemoved returns the reduction in size of the table. If the table is
+// larger after the purge than before, 0 is returned.
+func (sp *StatisticsHistoryPurge) BytesRemoved() uint64 {
+	if sp.BytesAfter > sp.BytesBefore {
+		return 0
+	}
+	return sp.BytesBefore - sp.BytesAfter
+}
+
+// AuditLogStats provides information about the entries held in the audit log
+// table. OldestEntry and NewestEntry are the zero time when the audit log is
+// empty.
+type AuditLogStats struct {
+	Entries        uint64    `json:"entries" yaml:"entries"`
+	OldestEntry    time.Time `json:"oldest_entry" yaml:"oldest_entry"`
+	NewestEntry    time.Time `json:"newest_entry" yaml:"newest_entry"`
+	TableSizeBytes uint64    `json:"table_size_bytes" yaml:"table_size_bytes"`
+}
+
+// SqlPlanCacheStats provides information about the number of plans held in the
+// SQL plan cache and the memory they use, summed across all services
+type SqlPlanCacheStats struct {
+	CachedPlans     uint64 `json:"cached_plans" yaml:"cached_plans"`
+	CachedPlanBytes uint64 `json:"cached_plan_bytes" yaml:"cached_plan_bytes"`
+	CapacityBytes   uint64 `json:"capacity_bytes" yaml:"capacity_bytes"`
+}
+
+// ExpensiveStatementsStats provides information about the number of entries
+// held by the expensive statements trace and the memory it uses
+type ExpensiveStatementsStats struct {
+	Entries     uint64 `json:"entries" yaml:"entries"`
+	MemoryBytes uint64 `json:"memory_bytes" yaml:"memory_bytes"`
+}
+
+// ClearStats provides information regarding the number of entries and the
+// amount of memory removed by clearing a cache or in-memory trace
+type ClearStats struct {
+	EntriesRemoved uint64 `json:"entries_removed" yaml:"entries_removed"`
+	BytesRemoved   uint64 `json:"bytes_removed" yaml:"bytes_removed"`
 }
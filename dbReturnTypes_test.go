@@ -1,6 +1,7 @@
 package hanautil
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -25,14 +26,20 @@ func TestBackupSummary_GetAllBytes(t *testing.T) {
 		OldestFullBackupDate      time.Time
 		OldestLogBackupDate       time.Time
 		CurrentDbTime             time.Time
+		Types                     map[string]BackupTypeStats
 	}
 	tests := []struct {
 		name   string
 		fields fields
 		want   uint64
 	}{
-		{"Good01", fields{100, 10, 90, 0, 0, 0, 0, 1024, 100, 0, 0, 0, 0, 0, t1, t1, t1}, 1124},
-		{"Good02", fields{100, 10, 90, 0, 0, 0, 0, 1024, 100, 500, 500, 500, 500, 500, t1, t1, t1}, 3624},
+		{"Good01", fields{100, 10, 90, 0, 0, 0, 0, 1024, 100, 0, 0, 0, 0, 0, t1, t1, t1, nil}, 1124},
+		{"Good02", fields{100, 10, 90, 0, 0, 0, 0, 1024, 100, 500, 500, 500, 500, 500, t1, t1, t1, nil}, 3624},
+		{"UnknownType", fields{100, 10, 80, 0, 0, 0, 0, 1024, 100, 0, 0, 0, 0, 0, t1, t1, t1, map[string]BackupTypeStats{
+			EntryTypeFullBackup: {10, 1024, BackupDates{}},
+			EntryTypeLogBackup:  {80, 100, BackupDates{}},
+			"tape backup":       {10, 4096, BackupDates{}},
+		}}, 5220},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,6 +61,7 @@ func TestBackupSummary_GetAllBytes(t *testing.T) {
 				OldestFullBackupDate:      tt.fields.OldestFullBackupDate,
 				OldestLogBackupDate:       tt.fields.OldestLogBackupDate,
 				CurrentDbTime:             tt.fields.CurrentDbTime,
+				Types:                     tt.fields.Types,
 			}
 			if got := bs.GetAllBytes(); got != tt.want {
 				t.Errorf("BackupSummary.GetAllBytes() = %v, want %v", got, tt.want)
@@ -61,3 +69,45 @@ func TestBackupSummary_GetAllBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestReclaimResult_BytesReclaimed(t *testing.T) {
+	tests := []struct {
+		name         string
+		services     []ServiceLogReclaim
+		wantBytes    uint64
+		wantSegments uint64
+	}{
+		{"NoServices", nil, 0, 0},
+		{"SingleService", []ServiceLogReclaim{{"hana01", 30003, "indexserver", 10, 1000, 1, 100}}, 900, 9},
+		{"MultipleServices", []ServiceLogReclaim{
+			{"hana01", 30001, "nameserver", 2, 200, 0, 0},
+			{"hana01", 30003, "indexserver", 10, 1000, 0, 0}}, 1200, 12},
+		/*A service gaining free log must not reduce the total of the others*/
+		{"ServiceGainedLog", []ServiceLogReclaim{
+			{"hana01", 30003, "indexserver", 10, 1000, 0, 0},
+			{"hana02", 30040, "indexserver", 0, 0, 5, 500}}, 1000, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := &ReclaimResult{Services: tt.services}
+			if got := rr.BytesReclaimed(); got != tt.wantBytes {
+				t.Errorf("ReclaimResult.BytesReclaimed() = %v, want %v", got, tt.wantBytes)
+			}
+			if got := rr.SegmentsReclaimed(); got != tt.wantSegments {
+				t.Errorf("ReclaimResult.SegmentsReclaimed() = %v, want %v", got, tt.wantSegments)
+			}
+		})
+	}
+}
+
+func TestBackupSummary_OtherTypes(t *testing.T) {
+	bs := &BackupSummary{Types: map[string]BackupTypeStats{
+		EntryTypeFullBackup: {1, 1024, BackupDates{}},
+		"tape backup":       {1, 2048, BackupDates{}},
+		"cloud backup":      {1, 4096, BackupDates{}},
+	}}
+	want := []string{"cloud backup", "tape backup"}
+	if got := bs.OtherTypes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("BackupSummary.OtherTypes() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+package hanautil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubClient is a Client that only implements Connect, Close and GetVersion.
+// Calling any other operation panics.
+type stubClient struct {
+	Client
+	version    string
+	connectErr error
+	delay      time.Duration
+	connects   atomic.Int32
+	running    *atomic.Int32
+	maxRunning *atomic.Int32
+}
+
+func (s *stubClient) Connect() error {
+	s.connects.Add(1)
+	return s.connectErr
+}
+
+func (s *stubClient) Close() error { return nil }
+
+func (s *stubClient) GetVersion() (string, error) {
+	if s.running != nil {
+		n := s.running.Add(1)
+		defer s.running.Add(-1)
+		for {
+			m := s.maxRunning.Load()
+			if n <= m || s.maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+	}
+	time.Sleep(s.delay)
+	return s.version, nil
+}
+
+func TestReadInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{"Valid", `{"systems": [{"name": "PRD", "dsn": "hdb://u:p@h1:30015"}, {"name": "QAS", "dsn": "hdb://u:p@h2:30015"}]}`, 2, false},
+		{"BadJSON", `{"systems": [`, 0, true},
+		{"NoDSN", `{"systems": [{"name": "PRD"}]}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "inventory.json")
+			err := os.WriteFile(p, []byte(tt.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := NewFleetFromInventory(p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFleetFromInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(f.Systems()) != tt.want {
+				t.Errorf("NewFleetFromInventory() has %d systems, want %d", len(f.Systems()), tt.want)
+			}
+		})
+	}
+
+	_, err := NewFleet(&Inventory{Systems: []FleetSystem{{Name: "PRD", DSN: "a"}, {Name: "PRD", DSN: "b"}}})
+	if err == nil {
+		t.Errorf("NewFleet() with duplicate names returned no error")
+	}
+}
+
+func TestRunFleet(t *testing.T) {
+	f := &Fleet{}
+	prd := &stubClient{version: "2.00.070.00"}
+	qas := &stubClient{version: "2.00.059.00"}
+	dev := &stubClient{connectErr: errors.New("ConnectionRefused")}
+	_ = f.Add("PRD", prd)
+	_ = f.Add("QAS", qas)
+	_ = f.Add("DEV", dev)
+
+	for i := 0; i < 2; i++ {
+		res := f.GetVersion(context.Background())
+		if len(res) != 3 {
+			t.Fatalf("GetVersion() returned %d results, want 3", len(res))
+		}
+		if res[0].System != "PRD" || res[0].Value != "2.00.070.00" || res[0].Err != nil {
+			t.Errorf("unexpected PRD result %+v", res[0])
+		}
+		if res[1].System != "QAS" || res[1].Value != "2.00.059.00" || res[1].Err != nil {
+			t.Errorf("unexpected QAS result %+v", res[1])
+		}
+		if res[2].System != "DEV" || res[2].Err == nil || res[2].Error != "ConnectionRefused" {
+			t.Errorf("unexpected DEV result %+v", res[2])
+		}
+		if res[0].Error != "" {
+			t.Errorf("PRD Error = %q, want none", res[0].Error)
+		}
+		err := FleetErrors(res)
+		if err == nil || err.Error() != "DEV: ConnectionRefused" {
+			t.Errorf("FleetErrors() = %v, want DEV: ConnectionRefused", err)
+		}
+	}
+
+	/*The error is kept when results are marshalled*/
+	b, err := json.Marshal(f.GetVersion(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []FleetResult[string]
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded[2].Error != "ConnectionRefused" || decoded[0].Error != "" {
+		t.Errorf("results after JSON round trip = %+v", decoded)
+	}
+
+	/*Connected systems are not connected again, failed ones are retried*/
+	if prd.connects.Load() != 1 || dev.connects.Load() != 3 {
+		t.Errorf("PRD connected %d times and DEV %d times, want 1 and 3", prd.connects.Load(), dev.connects.Load())
+	}
+	err = f.Close()
+	if err != nil {
+		t.Errorf("Fleet.Close() error = %v", err)
+	}
+}
+
+func TestRunFleet_Timeout(t *testing.T) {
+	f := &Fleet{Timeout: 20 * time.Millisecond}
+	_ = f.Add("FAST", &stubClient{version: "fast"})
+	_ = f.Add("SLOW", &stubClient{version: "slow", delay: time.Second})
+
+	res := f.GetVersion(context.Background())
+	if res[0].Err != nil || res[0].Value != "fast" {
+		t.Errorf("unexpected FAST result %+v", res[0])
+	}
+	if !errors.Is(res[1].Err, context.DeadlineExceeded) || res[1].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("SLOW error = %v (%q), want %v", res[1].Err, res[1].Error, context.DeadlineExceeded)
+	}
+	if res[1].Duration >= time.Second {
+		t.Errorf("SLOW took %v, want it abandoned after the timeout", res[1].Duration)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = f.GetVersion(ctx)
+	for _, r := range res {
+		if r.Error != context.Canceled.Error() {
+			t.Errorf("%s Error = %q, want %q", r.System, r.Error, context.Canceled.Error())
+		}
+	}
+}
+
+func TestRunFleet_TimeoutHoldsSystem(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	f := &Fleet{Parallelism: 1, Timeout: 10 * time.Millisecond}
+	_ = f.Add("A", &stubClient{version: "A", delay: 100 * time.Millisecond, running: &running, maxRunning: &maxRunning})
+	_ = f.Add("B", &stubClient{version: "B", delay: 100 * time.Millisecond, running: &running, maxRunning: &maxRunning})
+
+	/*An abandoned operation keeps its slot until it returns*/
+	res := f.GetVersion(context.Background())
+	for _, r := range res {
+		if !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("%s error = %v, want %v", r.System, r.Err, context.DeadlineExceeded)
+		}
+	}
+	if maxRunning.Load() > 1 {
+		t.Errorf("%d systems ran at once, want at most 1", maxRunning.Load())
+	}
+
+	/*A system is not reused while an abandoned operation is running*/
+	f = &Fleet{Timeout: 10 * time.Millisecond}
+	_ = f.Add("SLOW", &stubClient{version: "slow", delay: 200 * time.Millisecond})
+	_ = f.GetVersion(context.Background())
+	res = f.GetVersion(context.Background())
+	if res[0].Err == nil || res[0].Err.Error() != "SystemBusy: SLOW" || res[0].Error != "SystemBusy: SLOW" {
+		t.Errorf("SLOW error = %v (%q), want SystemBusy: SLOW", res[0].Err, res[0].Error)
+	}
+	time.Sleep(250 * time.Millisecond)
+	f.Timeout = 0
+	res = f.GetVersion(context.Background())
+	if res[0].Err != nil || res[0].Value != "slow" {
+		t.Errorf("unexpected SLOW result %+v", res[0])
+	}
+}
+
+func TestRunFleet_Parallelism(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	f := &Fleet{Parallelism: 2}
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		_ = f.Add(name, &stubClient{version: name, delay: 10 * time.Millisecond, running: &running, maxRunning: &maxRunning})
+	}
+
+	res := f.GetVersion(context.Background())
+	if err := FleetErrors(res); err != nil {
+		t.Fatal(err)
+	}
+	if maxRunning.Load() > 2 {
+		t.Errorf("%d systems ran at once, want at most 2", maxRunning.Load())
+	}
+
+	/*A cancelled context stops systems that have not started*/
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = f.GetVersion(ctx)
+	for _, r := range res {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s error = %v, want %v", r.System, r.Err, context.Canceled)
+		}
+	}
+}
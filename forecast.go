@@ -0,0 +1,148 @@
+package hanautil
+
+import (
+	"context"
+	"time"
+)
+
+/******************************************************************************/
+/* This file contains the backup catalog trend. The size of the catalog over  */
+/* time is read from the backups of the catalog itself, which HANA writes     */
+/* after every data and log backup.                                           */
+/******************************************************************************/
+
+// DefaultBackupCatalogThresholdBytes is a backup catalog size above which
+// backups and catalog operations are commonly found to slow down
+const DefaultBackupCatalogThresholdBytes uint64 = 50 << 20
+
+// maxForecastDays limits how far ahead the threshold is forecast
+const maxForecastDays = 100 * 365
+
+// BackupCatalogSample is the size of the backup catalog when it was backed up
+type BackupCatalogSample struct {
+	Time      time.Time `json:"time" yaml:"time"`
+	SizeBytes uint64    `json:"size_bytes" yaml:"size_bytes"`
+}
+
+// BackupCatalogTrend describes the growth of the backup catalog and forecasts
+// when it will exceed ThresholdBytes.
+//
+// GrowthBytesPerDay is the least squares growth rate of the samples taken
+// since GrowthSince, which is the first sample after the catalog last shrank,
+// usually because it was truncated. ThresholdTime is when the catalog is
+// forecast to exceed the threshold. It is the zero time if the threshold is
+// already exceeded, the catalog is not growing or the threshold would not be
+// reached within 100 years. All times are in UTC.
+type BackupCatalogTrend struct {
+	Samples           []BackupCatalogSample `json:"samples" yaml:"samples"`
+	CurrentSizeBytes  uint64                `json:"current_size_bytes" yaml:"current_size_bytes"`
+	GrowthBytesPerDay float64               `json:"growth_bytes_per_day" yaml:"growth_bytes_per_day"`
+	GrowthSince       time.Time             `json:"growth_since" yaml:"growth_since"`
+	ThresholdBytes    uint64                `json:"threshold_bytes" yaml:"threshold_bytes"`
+	ThresholdExceeded bool                  `json:"threshold_exceeded" yaml:"threshold_exceeded"`
+	ThresholdTime     time.Time             `json:"threshold_time" yaml:"threshold_time"`
+	CurrentDbTime     time.Time             `json:"current_db_time" yaml:"current_db_time"`
+}
+
+// NewBackupCatalogTrend returns the trend of 'samples', which must be ordered
+// by time, against a threshold of 'thresholdBytes'. 'now' is the current
+// database time.
+func NewBackupCatalogTrend(samples []BackupCatalogSample, thresholdBytes uint64, now time.Time) *BackupCatalogTrend {
+	bt := BackupCatalogTrend{Samples: samples, ThresholdBytes: thresholdBytes, CurrentDbTime: now}
+	if len(samples) == 0 {
+		return &bt
+	}
+	last := samples[len(samples)-1]
+	bt.CurrentSizeBytes = last.SizeBytes
+	bt.ThresholdExceeded = last.SizeBytes > thresholdBytes
+
+	/*Only samples since the catalog last shrank show its current growth*/
+	start := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i].SizeBytes < samples[i-1].SizeBytes {
+			start = i
+		}
+	}
+	growth := samples[start:]
+	bt.GrowthSince = growth[0].Time
+	bt.GrowthBytesPerDay = growthRate(growth)
+
+	if bt.ThresholdExceeded || bt.GrowthBytesPerDay <= 0 {
+		return &bt
+	}
+	days := float64(thresholdBytes-last.SizeBytes) / bt.GrowthBytesPerDay
+	if days <= maxForecastDays {
+		bt.ThresholdTime = last.Time.Add(time.Duration(days * float64(24*time.Hour)))
+	}
+	return &bt
+}
+
+// growthRate returns the least squares slope, in bytes per day, of the size of
+// 'samples' against time. Fewer than two distinct times have no growth.
+func growthRate(samples []BackupCatalogSample) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	origin := samples[0].Time
+	n := float64(len(samples))
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.Time.Sub(origin).Hours() / 24
+		sumY += float64(s.SizeBytes)
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxy, sxx float64
+	for _, s := range samples {
+		dx := s.Time.Sub(origin).Hours()/24 - meanX
+		sxy += dx * (float64(s.SizeBytes) - meanY)
+		sxx += dx * dx
+	}
+	if sxx == 0 {
+		return 0
+	}
+	return sxy / sxx
+}
+
+// GetBackupCatalogTrend reconstructs the size of the backup catalog over the
+// last 'days' days from the backups of the catalog, calculates its growth rate
+// and forecasts when it will exceed 'thresholdBytes', for example
+// DefaultBackupCatalogThresholdBytes, so that truncation can be scheduled
+// before it affects performance.
+func (h *HanaUtilClient) GetBackupCatalogTrend(days uint, thresholdBytes uint64) (*BackupCatalogTrend, error) {
+	ctx, span := h.startSpan(context.Background(), "GetBackupCatalogTrend")
+	defer span.End()
+
+	r1, err := h.query(ctx, "f_GetBackupCatalogHistory", f_GetBackupCatalogHistory(days))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	defer r1.Close()
+
+	samples := make([]BackupCatalogSample, 0)
+	for r1.Next() {
+		var s BackupCatalogSample
+		err = r1.Scan(&s.Time, &s.SizeBytes)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	err = r1.Err()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	var now time.Time
+	r2 := h.queryRow(ctx, "q_GetDbCurrentUtcTime", q_GetDbCurrentUtcTime)
+	err = r2.Scan(&now)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	return NewBackupCatalogTrend(samples, thresholdBytes, now), nil
+}
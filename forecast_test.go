@@ -0,0 +1,137 @@
+package hanautil
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestNewBackupCatalogTrend(t *testing.T) {
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	day := func(d int, size uint64) BackupCatalogSample {
+		return BackupCatalogSample{genTime.AddDate(0, 0, d), size}
+	}
+	tests := []struct {
+		name          string
+		samples       []BackupCatalogSample
+		threshold     uint64
+		wantGrowth    float64
+		wantSince     time.Time
+		wantExceeded  bool
+		wantThreshold time.Time
+	}{
+		{"NoSamples", nil, 1000, 0, time.Time{}, false, time.Time{}},
+		{"OneSample", []BackupCatalogSample{day(0, 100)}, 1000, 0, genTime, false, time.Time{}},
+		{"Linear", []BackupCatalogSample{day(0, 100), day(1, 200), day(2, 300)}, 1000, 100, genTime, false, genTime.AddDate(0, 0, 9)},
+		{"Truncated", []BackupCatalogSample{day(0, 500), day(1, 900), day(2, 100), day(3, 150), day(4, 200)}, 1000, 50, genTime.AddDate(0, 0, 2), false, genTime.AddDate(0, 0, 20)},
+		{"Exceeded", []BackupCatalogSample{day(0, 900), day(1, 1100)}, 1000, 200, genTime, true, time.Time{}},
+		{"Flat", []BackupCatalogSample{day(0, 100), day(1, 100)}, 1000, 0, genTime, false, time.Time{}},
+		{"SameTime", []BackupCatalogSample{day(0, 100), day(0, 200)}, 1000, 0, genTime, false, time.Time{}},
+		{"TooSlow", []BackupCatalogSample{day(0, 100), day(1, 101)}, math.MaxUint32, 1, genTime, false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewBackupCatalogTrend(tt.samples, tt.threshold, genTime)
+			if math.Abs(got.GrowthBytesPerDay-tt.wantGrowth) > 1e-9 {
+				t.Errorf("GrowthBytesPerDay = %v, want %v", got.GrowthBytesPerDay, tt.wantGrowth)
+			}
+			if !got.GrowthSince.Equal(tt.wantSince) {
+				t.Errorf("GrowthSince = %v, want %v", got.GrowthSince, tt.wantSince)
+			}
+			if got.ThresholdExceeded != tt.wantExceeded {
+				t.Errorf("ThresholdExceeded = %v, want %v", got.ThresholdExceeded, tt.wantExceeded)
+			}
+			if !got.ThresholdTime.Equal(tt.wantThreshold) {
+				t.Errorf("ThresholdTime = %v, want %v", got.ThresholdTime, tt.wantThreshold)
+			}
+			if got.ThresholdBytes != tt.threshold || !got.CurrentDbTime.Equal(genTime) {
+				t.Errorf("unexpected trend %+v", got)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_GetBackupCatalogTrend(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"UTC_START_TIME", "BACKUP_SIZE"}
+	samples := []BackupCatalogSample{
+		{genTime.AddDate(0, 0, -2), 1000},
+		{genTime.AddDate(0, 0, -1), 2000},
+	}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *BackupCatalogTrend
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &BackupCatalogTrend{
+			Samples:           samples,
+			CurrentSizeBytes:  2000,
+			GrowthBytesPerDay: 1000,
+			GrowthSince:       genTime.AddDate(0, 0, -2),
+			ThresholdBytes:    10000,
+			ThresholdTime:     genTime.AddDate(0, 0, 7),
+			CurrentDbTime:     genTime,
+		}, false},
+		{"GoodEmpty", fields{db1, ""}, &BackupCatalogTrend{Samples: []BackupCatalogSample{}, ThresholdBytes: 10000, CurrentDbTime: genTime}, false},
+		{"DbError", fields{db1, ""}, nil, true},
+		{"ScanError", fields{db1, ""}, nil, true},
+		{"TimeError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows := mock.NewRows(cols).AddRow(samples[0].Time, samples[0].SizeBytes).AddRow(samples[1].Time, samples[1].SizeBytes)
+			mock.ExpectQuery(f_GetBackupCatalogHistory(30)).WillReturnRows(rows)
+			mock.ExpectQuery(q_GetDbCurrentUtcTime).WillReturnRows(mock.NewRows([]string{"CURRENT_TIME"}).AddRow(genTime))
+		case "GoodEmpty":
+			mock.ExpectQuery(f_GetBackupCatalogHistory(30)).WillReturnRows(mock.NewRows(cols))
+			mock.ExpectQuery(q_GetDbCurrentUtcTime).WillReturnRows(mock.NewRows([]string{"CURRENT_TIME"}).AddRow(genTime))
+		case "DbError":
+			mock.ExpectQuery(f_GetBackupCatalogHistory(30)).WillReturnError(fmt.Errorf("DbError"))
+		case "ScanError":
+			rows := mock.NewRows(cols).AddRow(genTime, "big")
+			mock.ExpectQuery(f_GetBackupCatalogHistory(30)).WillReturnRows(rows)
+		case "TimeError":
+			mock.ExpectQuery(f_GetBackupCatalogHistory(30)).WillReturnRows(mock.NewRows(cols))
+			mock.ExpectQuery(q_GetDbCurrentUtcTime).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.GetBackupCatalogTrend(30, 10000)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.GetBackupCatalogTrend() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.GetBackupCatalogTrend() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
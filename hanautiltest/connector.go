@@ -0,0 +1,230 @@
+package hanautiltest
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+/******************************************************************************/
+/* This file contains the Connector, a database/sql driver that answers the   */
+/* statements of a HanaUtilClient from fixture data. It lets the client be    */
+/* connected and its queries scanned through database/sql without a HANA     */
+/* database. It replaces go-hdb rather than speaking the HANA wire protocol, */
+/* so NewClient, DSNs and go-hdb's type conversions are not exercised.        */
+/******************************************************************************/
+
+// Result is the fixture answer to a statement. Queries return Columns and
+// Rows, other statements report RowsAffected. If Err is set the statement
+// fails with it.
+type Result struct {
+	Columns      []string
+	Rows         [][]any
+	RowsAffected int64
+	Err          error
+}
+
+// Statement is a statement run through a Connector with its bind parameters
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// fixture answers the statements that contain 'match'
+type fixture struct {
+	match  string
+	result Result
+}
+
+// Connector is a driver.Connector that answers statements from fixtures
+// instead of a HANA database, for use with hanautil.NewClientFromConnector:
+//
+//	c := hanautiltest.NewConnector()
+//	c.Handle(`"SYS"."M_DATABASE"`, hanautiltest.Result{
+//		Columns: []string{"VERSION"},
+//		Rows:    [][]any{{"2.00.070.00"}},
+//	})
+//	h := hanautil.NewClientFromConnector(c)
+//
+// A statement is answered by the first fixture, in the order they were
+// added, whose match is contained in the statement's text, for example the
+// name of the SYS view it reads. Statements without a fixture fail with the
+// error 'NoFixture'. Transactions are accepted and have no effect.
+//
+// If PingErr is set, connecting to the database fails with it. PingErr should
+// only be changed while no operation is running.
+//
+// The Connector is not a HANA wire-protocol stand-in. Clients created by
+// hanautil.NewClient from a DSN always use go-hdb and need a HANA database.
+type Connector struct {
+	PingErr error
+
+	mu         sync.Mutex
+	fixtures   []fixture
+	statements []Statement
+}
+
+// Connector must implement driver.Connector
+var _ driver.Connector = (*Connector)(nil)
+
+// NewConnector returns a Connector without fixtures
+func NewConnector() *Connector {
+	return &Connector{}
+}
+
+// Handle answers the statements that contain 'match' with 'r'. The values of
+// Rows are converted as database/sql converts bind parameters, so any integer
+// type may be used. Handle panics if a value cannot be converted.
+func (c *Connector) Handle(match string, r Result) {
+	rows := make([][]any, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		values := make([]any, len(row))
+		for i, v := range row {
+			dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				panic(fmt.Sprintf("hanautiltest: fixture %q: %v", match, err))
+			}
+			values[i] = dv
+		}
+		rows = append(rows, values)
+	}
+	r.Rows = rows
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fixtures = append(c.fixtures, fixture{match, r})
+}
+
+// Statements returns the statements run so far in the order they were run
+func (c *Connector) Statements() []Statement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Statement(nil), c.statements...)
+}
+
+// answer records the statement 'query' and returns its fixture
+func (c *Connector) answer(query string, args []driver.NamedValue) (Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := Statement{Query: query}
+	for _, a := range args {
+		s.Args = append(s.Args, a.Value)
+	}
+	c.statements = append(c.statements, s)
+
+	for _, f := range c.fixtures {
+		if strings.Contains(query, f.match) {
+			return f.result, f.result.Err
+		}
+	}
+	return Result{}, fmt.Errorf("NoFixture: %s", query)
+}
+
+func (c *Connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{c: c}, nil
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return connectorDriver{c}
+}
+
+// connectorDriver is the driver of a Connector. It does not open DSNs.
+type connectorDriver struct{ c *Connector }
+
+func (d connectorDriver) Open(string) (driver.Conn, error) {
+	return d.c.Connect(context.Background())
+}
+
+// conn is a connection of a Connector
+type conn struct{ c *Connector }
+
+func (cn *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{cn: cn, query: query}, nil
+}
+
+func (cn *conn) Close() error { return nil }
+
+func (cn *conn) Begin() (driver.Tx, error) { return tx{}, nil }
+
+func (cn *conn) Ping(context.Context) error {
+	cn.c.mu.Lock()
+	defer cn.c.mu.Unlock()
+	return cn.c.PingErr
+}
+
+func (cn *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, err := cn.c.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: r.Columns, values: r.Rows}, nil
+}
+
+func (cn *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r, err := cn.c.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(r.RowsAffected), nil
+}
+
+// stmt is a prepared statement of a conn, used only if database/sql cannot
+// run a statement on the conn directly
+type stmt struct {
+	cn    *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.cn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.cn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+// namedValues returns 'args' as positional named values
+func namedValues(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return nv
+}
+
+// tx is a transaction of a conn, which has no effect
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+// rows are the rows of a Result
+type rows struct {
+	columns []string
+	values  [][]any
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	row := r.values[r.next]
+	r.next++
+	if len(row) != len(dest) {
+		return fmt.Errorf("FixtureColumnMismatch: row has %d values, want %d", len(row), len(dest))
+	}
+	for i := range dest {
+		dest[i] = row[i]
+	}
+	return nil
+}
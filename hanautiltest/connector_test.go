@@ -0,0 +1,129 @@
+package hanautiltest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+// newConnector returns a Connector with fixtures for the SYS views read by the
+// tests below
+func newConnector() *Connector {
+	c := NewConnector()
+	c.Handle(`"SYS"."M_DATABASE"`, Result{
+		Columns: []string{"VERSION"},
+		Rows:    [][]any{{"2.00.070.00"}},
+	})
+	c.Handle(`"SYS"."M_BACKUP_CATALOG"`, Result{
+		Columns: []string{"ENTRY_TYPE_NAME", "ENTRIES", "BYTES", "OLDEST", "NEWEST", "CATALOG_SIZE", "CURRENT_TIME"},
+		Rows: [][]any{
+			{BackupFull, 2, uint64(2048), genTime.AddDate(0, 0, -2), genTime.AddDate(0, 0, -1), 512, genTime},
+			{BackupLog, 10, 100, genTime.AddDate(0, 0, -2), genTime.Add(-time.Hour), 512, genTime},
+			{"tape backup", 1, 4096, nil, nil, 512, genTime},
+		},
+	})
+	c.Handle("ALTER SYSTEM REMOVE TRACES", Result{})
+	c.Handle(`"SYS"."M_TRACEFILES" WHERE HOST = ?`, Result{
+		Columns: []string{"COUNT"},
+		Rows:    [][]any{{1}},
+	})
+	c.Handle(`"SYS"."M_TRACEFILES"`, Result{
+		Columns: []string{"HOST", "FILE_NAME", "FILE_SIZE", "FILE_MTIME"},
+		Rows:    [][]any{{"hana01", "old.trc", 100, genTime.AddDate(0, 0, -30)}},
+	})
+	c.Handle(`DELETE FROM "_SYS_STATISTICS"."STATISTICS_ALERTS_BASE"`, Result{RowsAffected: 3})
+	return c
+}
+
+func TestConnector_Client(t *testing.T) {
+	c := newConnector()
+	h := hanautil.NewClientFromConnector(c)
+	err := h.Connect()
+	if err != nil {
+		t.Fatalf("HanaUtilClient.Connect() error = %v", err)
+	}
+	defer h.Close()
+
+	v, err := h.GetVersion()
+	if err != nil || v != "2.00.070.00" {
+		t.Errorf("HanaUtilClient.GetVersion() = %v, %v, want 2.00.070.00", v, err)
+	}
+
+	bs, err := h.GetBackupSummary()
+	if err != nil {
+		t.Fatalf("HanaUtilClient.GetBackupSummary() error = %v", err)
+	}
+	if bs.FullBackups != 2 || bs.SizeOfFullBackupsBytes != 2048 || bs.LogBackups != 10 || bs.BackupCatalogEntries != 13 {
+		t.Errorf("unexpected backup summary %+v", bs)
+	}
+	if bs.LogBackupDates.NewestAgeSeconds != 3600 || !bs.CurrentDbTime.Equal(genTime) {
+		t.Errorf("unexpected log backup dates %+v at %v", bs.LogBackupDates, bs.CurrentDbTime)
+	}
+	if got := bs.OtherTypes(); !reflect.DeepEqual(got, []string{"tape backup"}) {
+		t.Errorf("BackupSummary.OtherTypes() = %v, want [tape backup]", got)
+	}
+
+	tf, err := h.GetTraceFiles(7)
+	if err != nil || len(tf) != 1 || !tf[0].LastModified.Equal(genTime.AddDate(0, 0, -30)) {
+		t.Errorf("HanaUtilClient.GetTraceFiles() = %+v, %v", tf, err)
+	}
+
+	/*The fixture still finds the file after it was removed*/
+	err = h.RemoveTraceFile("hana01", "x') ; --")
+	if err == nil || err.Error() != "TraceFileNotRemoved" {
+		t.Errorf("HanaUtilClient.RemoveTraceFile() error = %v, want TraceFileNotRemoved", err)
+	}
+
+	ds, err := h.RemoveStatServerAlertsBatched(context.Background(), 30, hanautil.BatchOptions{BatchSize: 10})
+	if err != nil || ds.TotalRemoved != 3 {
+		t.Errorf("HanaUtilClient.RemoveStatServerAlertsBatched() = %+v, %v", ds, err)
+	}
+
+	var lookup, remove *Statement
+	for _, s := range c.Statements() {
+		switch {
+		case strings.Contains(s.Query, "HOST = ?"):
+			lookup = &s
+		case strings.HasPrefix(s.Query, "ALTER SYSTEM REMOVE TRACES"):
+			remove = &s
+		}
+	}
+	if lookup == nil || !reflect.DeepEqual(lookup.Args, []any{"hana01", "x') ; --"}) {
+		t.Errorf("trace file lookup = %+v, want bind parameters", lookup)
+	}
+	if remove == nil || !strings.Contains(remove.Query, "'x'') ; --'") {
+		t.Errorf("trace file removal = %+v, want a quoted file name", remove)
+	}
+}
+
+func TestConnector_Errors(t *testing.T) {
+	c := newConnector()
+	c.PingErr = errors.New("PingError")
+	h := hanautil.NewClientFromConnector(c)
+	err := h.Connect()
+	if err == nil || err.Error() != "PingError" {
+		t.Errorf("HanaUtilClient.Connect() error = %v, want PingError", err)
+	}
+
+	c.PingErr = nil
+	err = h.Connect()
+	if err != nil {
+		t.Fatalf("HanaUtilClient.Connect() error = %v", err)
+	}
+	defer h.Close()
+	_, err = h.GetLogSegmentStats()
+	if err == nil || !strings.HasPrefix(err.Error(), "NoFixture") {
+		t.Errorf("HanaUtilClient.GetLogSegmentStats() error = %v, want NoFixture", err)
+	}
+
+	c.Handle(`"SYS"."M_LOG_SEGMENTS"`, Result{Err: errors.New("DbError")})
+	_, err = h.GetLogSegmentStats()
+	if err == nil || err.Error() != "DbError" {
+		t.Errorf("HanaUtilClient.GetLogSegmentStats() error = %v, want DbError", err)
+	}
+}
@@ -0,0 +1,898 @@
+// Package hanautiltest provides an in-memory fake of hanautil.Client for use
+// in tests of code that automates HANA monitoring and housekeeping.
+//
+// The Fake holds trace files, the backup catalog, log segments, statistics
+// server alerts and audit log entries in memory. Destructive operations change
+// that state, so a test can call TruncateBackupCatalog and then see a smaller
+// catalog reported by GetBackupSummary. Other information, such as disk and
+// memory usage, is returned as set by the test.
+package hanautiltest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+// Backup types, as named in the HANA backup catalog
+const (
+	BackupFull         = hanautil.EntryTypeFullBackup
+	BackupLog          = hanautil.EntryTypeLogBackup
+	BackupIncremental  = hanautil.EntryTypeIncrementalBackup
+	BackupDifferential = hanautil.EntryTypeDifferentialBackup
+	BackupLogMissing   = hanautil.EntryTypeLogMissing
+	BackupDataSnapshot = hanautil.EntryTypeDataSnapshot
+)
+
+// TraceFile is a trace file held by the Fake. A trace file that is Open cannot
+// be removed.
+type TraceFile struct {
+	hanautil.TraceFile
+	Open bool
+}
+
+// Backup is a single entry of the backup catalog held by the Fake. Entries are
+// ordered by ID, which increases with Start as in HANA. Each entry is treated
+// as a single backup file of SizeBytes. CatalogSizeBytes is the size of the
+// backup catalog backed up with the entry, zero if it was not.
+type Backup struct {
+	ID               uint64
+	Type             string
+	Start            time.Time
+	Failed           bool
+	SizeBytes        uint64
+	CatalogSizeBytes uint64
+}
+
+// LogSegment is a single log segment held by the Fake. Free segments are
+// removed by ReclaimLog.
+type LogSegment struct {
+	Hostname    string
+	Port        uint32
+	ServiceName string
+	Free        bool
+	SizeBytes   uint64
+}
+
+// Fake is an in-memory implementation of hanautil.Client. The zero value is an
+// empty, connected database. Fields may be set directly before the Fake is
+// used, after which they should only be changed while no operation is
+// running.
+//
+// As in HANA, the size of the backup catalog reported by a backup summary is
+// the CatalogSizeBytes of the newest successful entry selected that has one.
+// If no entry has a CatalogSizeBytes, BackupCatalogSizeBytes is used as that
+// of the newest successful entry.
+//
+// If Errors holds an error for the name of an operation, such as
+// "TruncateBackupCatalog", the operation returns that error without changing
+// any state. StrictBackupTypes behaves as
+// HanaUtilClient.SetStrictBackupTypes, failing backup summaries with the error
+// 'UnexpectedBackupType' if Backups holds a type other than those above.
+type Fake struct {
+	mu sync.Mutex
+
+	// Now is the current database time, if zero time.Now is used
+	Now time.Time
+
+	Version                string
+	TraceFiles             []TraceFile
+	Backups                []Backup
+	BackupCatalogSizeBytes uint64
+	BackupCatalogHistory   []hanautil.BackupCatalogSample
+	LogSegments            []LogSegment
+	Alerts                 []hanautil.StatServerAlert
+	AuditLog               []time.Time
+	DiskUsage              []hanautil.DiskUsage
+	VolumeUsage            []hanautil.VolumeUsage
+	MemoryUsage            hanautil.MemoryUsage
+	Tables                 []hanautil.TableSize
+	TableUnloads           []hanautil.TableUnload
+	DeltaMergeCandidates   []hanautil.DeltaMergeCandidate
+	StatisticsHistory      []hanautil.StatisticsHistoryTable
+	SqlPlanCache           hanautil.SqlPlanCacheStats
+	ExpensiveStatements    hanautil.ExpensiveStatementsStats
+	StrictBackupTypes      bool
+	Errors                 map[string]error
+	closed                 bool
+}
+
+// Fake must implement hanautil.Client
+var _ hanautil.Client = (*Fake)(nil)
+
+// now returns the current database time
+func (f *Fake) now() time.Time {
+	if f.Now.IsZero() {
+		return time.Now()
+	}
+	return f.Now
+}
+
+// cutoff returns the time 'days' days before the current database time
+func (f *Fake) cutoff(days uint) time.Time {
+	return f.now().AddDate(0, 0, -int(days))
+}
+
+// fail returns the error set for the operation 'name', if any
+func (f *Fake) fail(name string) error {
+	if f.closed {
+		return sql.ErrConnDone
+	}
+	return f.Errors[name]
+}
+
+func (f *Fake) Connect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = false
+	return f.Errors["Connect"]
+}
+
+func (f *Fake) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return f.Errors["Close"]
+}
+
+func (f *Fake) GetVersion() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetVersion"); err != nil {
+		return "", err
+	}
+	return f.Version, nil
+}
+
+func (f *Fake) GetTraceFiles(days uint) ([]hanautil.TraceFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetTraceFiles"); err != nil {
+		return nil, err
+	}
+	return f.traceFiles(days), nil
+}
+
+// traceFiles returns the trace files older than 'days'. As in HANA, only
+// files ending in trc or gz are returned.
+func (f *Fake) traceFiles(days uint) []hanautil.TraceFile {
+	cutoff := f.cutoff(days)
+	tf := make([]hanautil.TraceFile, 0)
+	for _, t := range f.TraceFiles {
+		if !strings.HasSuffix(t.FileName, "trc") && !strings.HasSuffix(t.FileName, "gz") {
+			continue
+		}
+		if t.LastModified.Before(cutoff) {
+			tf = append(tf, t.TraceFile)
+		}
+	}
+	return tf
+}
+
+func (f *Fake) RemoveTraceFile(host, filename string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("RemoveTraceFile"); err != nil {
+		return err
+	}
+	for i, t := range f.TraceFiles {
+		if t.Hostname != host || t.FileName != filename {
+			continue
+		}
+		if t.Open {
+			return fmt.Errorf("TraceFileNotRemoved")
+		}
+		f.TraceFiles = append(f.TraceFiles[:i], f.TraceFiles[i+1:]...)
+		return nil
+	}
+	return fmt.Errorf("TraceFileNotFound")
+}
+
+// dates tracks the oldest and newest start times of a backup type
+type dates struct{ oldest, newest time.Time }
+
+func (d *dates) add(t time.Time) {
+	if d.oldest.IsZero() || t.Before(d.oldest) {
+		d.oldest = t
+	}
+	if d.newest.IsZero() || t.After(d.newest) {
+		d.newest = t
+	}
+}
+
+// backupSummary summarises the catalog entries selected by 'filter'
+func (f *Fake) backupSummary(filter hanautil.BackupFilter) (*hanautil.BackupSummary, error) {
+	var before uint64
+	if filter.BeforeBackupID != "" {
+		var err error
+		before, err = strconv.ParseUint(filter.BeforeBackupID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("InvalidBackupID")
+		}
+	}
+
+	catalogSizes := f.catalogSizes()
+	var catalogBytes uint64
+	var catalogStart time.Time
+	types := make(map[string]hanautil.BackupTypeStats)
+	typeDates := make(map[string]*dates)
+	for i, b := range f.Backups {
+		if before != 0 && b.ID >= before ||
+			!filter.From.IsZero() && b.Start.Before(filter.From) ||
+			!filter.To.IsZero() && !b.Start.Before(filter.To) {
+			continue
+		}
+		switch b.Type {
+		case BackupFull, BackupLog, BackupIncremental, BackupDifferential, BackupLogMissing, BackupDataSnapshot:
+		default:
+			if f.StrictBackupTypes {
+				return nil, fmt.Errorf("UnexpectedBackupType")
+			}
+		}
+		t := types[b.Type]
+		t.Count++
+		t.SizeBytes += b.SizeBytes
+		types[b.Type] = t
+		if typeDates[b.Type] == nil {
+			typeDates[b.Type] = &dates{}
+		}
+		/*As in HANA, log missing entries have no dates*/
+		if b.Type != BackupLogMissing {
+			typeDates[b.Type].add(b.Start)
+		}
+		if catalogSizes[i] != 0 && !b.Failed && !b.Start.Before(catalogStart) {
+			catalogBytes, catalogStart = catalogSizes[i], b.Start
+		}
+	}
+
+	for name, t := range types {
+		d := typeDates[name]
+		t.Dates.Oldest, t.Dates.Newest = d.oldest, d.newest
+		types[name] = t
+	}
+	return hanautil.NewBackupSummary(types, catalogBytes, f.now().UTC()), nil
+}
+
+// catalogSizes returns the size of the backup catalog backed up with each
+// entry of Backups
+func (f *Fake) catalogSizes() []uint64 {
+	sizes := make([]uint64, len(f.Backups))
+	set := false
+	newest := -1
+	for i, b := range f.Backups {
+		sizes[i] = b.CatalogSizeBytes
+		set = set || b.CatalogSizeBytes != 0
+		if !b.Failed && (newest < 0 || !b.Start.Before(f.Backups[newest].Start)) {
+			newest = i
+		}
+	}
+	if !set && newest >= 0 {
+		sizes[newest] = f.BackupCatalogSizeBytes
+	}
+	return sizes
+}
+
+func (f *Fake) GetBackupSummary() (*hanautil.BackupSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetBackupSummary"); err != nil {
+		return nil, err
+	}
+	return f.backupSummary(hanautil.BackupFilter{})
+}
+
+// fullBackupID returns the ID of the latest successful full backup older than
+// 'days', or sql.ErrNoRows if there is none
+func (f *Fake) fullBackupID(days int) (uint64, error) {
+	cutoff := f.cutoff(uint(days))
+	var id uint64
+	var start time.Time
+	for _, b := range f.Backups {
+		if b.Type == BackupFull && !b.Failed && b.Start.Before(cutoff) && b.Start.After(start) {
+			id, start = b.ID, b.Start
+		}
+	}
+	if id == 0 {
+		return 0, sql.ErrNoRows
+	}
+	return id, nil
+}
+
+func (f *Fake) GetFullBackupId(days int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetFullBackupId"); err != nil {
+		return "", err
+	}
+	id, err := f.fullBackupID(days)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(id, 10), nil
+}
+
+func (f *Fake) GetBackupSummaryBeforeBackupID(b string) (*hanautil.BackupSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetBackupSummaryBeforeBackupID"); err != nil {
+		return nil, err
+	}
+	return f.backupSummary(hanautil.BackupFilter{BeforeBackupID: b})
+}
+
+func (f *Fake) GetBackupFilesBeforeBackupID(b string) (*hanautil.BackupFileStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetBackupFilesBeforeBackupID"); err != nil {
+		return nil, err
+	}
+	before, err := strconv.ParseUint(b, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidBackupID")
+	}
+	fs := hanautil.BackupFileStats{}
+	for _, bk := range f.Backups {
+		if bk.ID < before {
+			fs.Files++
+			fs.Bytes += bk.SizeBytes
+		}
+	}
+	return &fs, nil
+}
+
+func (f *Fake) GetBackupSummaryFiltered(filter hanautil.BackupFilter) (*hanautil.BackupSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetBackupSummaryFiltered"); err != nil {
+		return nil, err
+	}
+	return f.backupSummary(filter)
+}
+
+func (f *Fake) GetLatestBackups() (*hanautil.LatestBackups, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetLatestBackups"); err != nil {
+		return nil, err
+	}
+	lb := hanautil.LatestBackups{CurrentDbTime: f.now()}
+	for _, b := range f.Backups {
+		if b.Failed {
+			continue
+		}
+		switch {
+		case b.Type == BackupFull && b.Start.After(lb.FullBackup):
+			lb.FullBackup = b.Start
+		case b.Type == BackupLog && b.Start.After(lb.LogBackup):
+			lb.LogBackup = b.Start
+		}
+	}
+	return &lb, nil
+}
+
+func (f *Fake) GetBackupCatalogTrend(days uint, thresholdBytes uint64) (*hanautil.BackupCatalogTrend, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetBackupCatalogTrend"); err != nil {
+		return nil, err
+	}
+	cutoff := f.cutoff(days)
+	samples := make([]hanautil.BackupCatalogSample, 0)
+	for _, s := range f.BackupCatalogHistory {
+		if !s.Time.Before(cutoff) {
+			samples = append(samples, s)
+		}
+	}
+	return hanautil.NewBackupCatalogTrend(samples, thresholdBytes, f.now().UTC()), nil
+}
+
+func (f *Fake) TruncateBackupCatalog(days int, complete bool) (*hanautil.TruncateStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("TruncateBackupCatalog"); err != nil {
+		return nil, err
+	}
+	id, err := f.fullBackupID(days)
+	if err != nil {
+		return nil, err
+	}
+	return f.truncateBefore(id, complete), nil
+}
+
+func (f *Fake) TruncateBackupCatalogBeforeBackupID(b string, complete bool) (*hanautil.TruncateStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("TruncateBackupCatalogBeforeBackupID"); err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(b, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("InvalidBackupID")
+	}
+	return f.truncateBefore(id, complete), nil
+}
+
+// truncateBefore removes the backups before the backup 'id' from the catalog
+func (f *Fake) truncateBefore(id uint64, complete bool) *hanautil.TruncateStats {
+	ts := hanautil.TruncateStats{}
+	kept := make([]Backup, 0, len(f.Backups))
+	for _, b := range f.Backups {
+		if b.ID >= id {
+			kept = append(kept, b)
+			continue
+		}
+		ts.FilesRemoved++
+		/*Bytes are only removed when the backup files are destroyed*/
+		if complete {
+			ts.BytesRemoved += b.SizeBytes
+		}
+	}
+	f.Backups = kept
+	return &ts
+}
+
+// alerts returns the number of alerts older than 'days'
+func (f *Fake) alerts(days uint) uint64 {
+	cutoff := f.cutoff(days)
+	var n uint64
+	for _, a := range f.Alerts {
+		if a.Timestamp.Before(cutoff) {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *Fake) GetStatServerAlerts(days uint) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetStatServerAlerts"); err != nil {
+		return 0, err
+	}
+	return uint(f.alerts(days)), nil
+}
+
+// removeAlerts removes up to 'limit' alerts older than 'days', all of them if
+// 'limit' is 0, and returns the number removed
+func (f *Fake) removeAlerts(days uint, limit uint64) uint64 {
+	cutoff := f.cutoff(days)
+	var removed uint64
+	kept := make([]hanautil.StatServerAlert, 0, len(f.Alerts))
+	for _, a := range f.Alerts {
+		if a.Timestamp.Before(cutoff) && (limit == 0 || removed < limit) {
+			removed++
+			continue
+		}
+		kept = append(kept, a)
+	}
+	f.Alerts = kept
+	return removed
+}
+
+func (f *Fake) RemoveStatServerAlerts(days uint) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("RemoveStatServerAlerts"); err != nil {
+		return 0, err
+	}
+	return f.removeAlerts(days, 0), nil
+}
+
+func (f *Fake) RemoveStatServerAlertsBatched(ctx context.Context, days uint, opts hanautil.BatchOptions) (*hanautil.BatchDeleteStats, error) {
+	bs := hanautil.BatchDeleteStats{RowsPerBatch: make([]uint64, 0)}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = hanautil.DefaultBatchSize
+	}
+
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return &bs, err
+		}
+
+		f.mu.Lock()
+		err = f.fail("RemoveStatServerAlertsBatched")
+		var removed uint64
+		if err == nil {
+			removed = f.removeAlerts(days, uint64(batchSize))
+		}
+		f.mu.Unlock()
+		if err != nil {
+			return &bs, err
+		}
+
+		bs.RowsPerBatch = append(bs.RowsPerBatch, removed)
+		bs.TotalRemoved += removed
+		if opts.Progress != nil {
+			opts.Progress(hanautil.BatchProgress{
+				Batch:        uint(len(bs.RowsPerBatch)),
+				RowsRemoved:  removed,
+				TotalRemoved: bs.TotalRemoved,
+			})
+		}
+		if removed < uint64(batchSize) {
+			return &bs, nil
+		}
+	}
+}
+
+// matchAlert returns true if 'a' passes 'filter'
+func matchAlert(a hanautil.StatServerAlert, filter hanautil.StatServerAlertFilter) bool {
+	if a.Rating < filter.MinRating {
+		return false
+	}
+	if !filter.From.IsZero() && a.Timestamp.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !a.Timestamp.Before(filter.To) {
+		return false
+	}
+	return true
+}
+
+func (f *Fake) ListStatServerAlerts(filter hanautil.StatServerAlertFilter) ([]hanautil.StatServerAlert, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ListStatServerAlerts"); err != nil {
+		return nil, err
+	}
+	alerts := make([]hanautil.StatServerAlert, 0)
+	for _, a := range f.Alerts {
+		if matchAlert(a, filter) {
+			alerts = append(alerts, a)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].Timestamp.After(alerts[j].Timestamp) })
+	if filter.Limit > 0 && uint(len(alerts)) > filter.Limit {
+		alerts = alerts[:filter.Limit]
+	}
+	return alerts, nil
+}
+
+func (f *Fake) SummariseStatServerAlerts(filter hanautil.StatServerAlertFilter) ([]hanautil.StatServerAlertSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("SummariseStatServerAlerts"); err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*hanautil.StatServerAlertSummary)
+	summary := make([]hanautil.StatServerAlertSummary, 0)
+	for _, a := range f.Alerts {
+		if !matchAlert(a, filter) {
+			continue
+		}
+		s, ok := byID[a.AlertID]
+		if !ok {
+			s = &hanautil.StatServerAlertSummary{AlertID: a.AlertID, AlertName: a.AlertName, FirstTimestamp: a.Timestamp, LastTimestamp: a.Timestamp}
+			byID[a.AlertID] = s
+		}
+		s.Count++
+		if a.Rating > s.MaxRating {
+			s.MaxRating = a.Rating
+		}
+		if a.Timestamp.Before(s.FirstTimestamp) {
+			s.FirstTimestamp = a.Timestamp
+		}
+		if a.Timestamp.After(s.LastTimestamp) {
+			s.LastTimestamp = a.Timestamp
+		}
+	}
+	for _, s := range byID {
+		summary = append(summary, *s)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].MaxRating != summary[j].MaxRating {
+			return summary[i].MaxRating > summary[j].MaxRating
+		}
+		if summary[i].Count != summary[j].Count {
+			return summary[i].Count > summary[j].Count
+		}
+		return summary[i].AlertID < summary[j].AlertID
+	})
+	return summary, nil
+}
+
+func (f *Fake) GetLogSegmentStats() (*hanautil.LogSegmentsStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetLogSegmentStats"); err != nil {
+		return nil, err
+	}
+	ls := hanautil.LogSegmentsStats{}
+	for _, s := range f.LogSegments {
+		if s.Free {
+			ls.FreeSegments++
+			ls.TotalFreeSegmentBytes += s.SizeBytes
+		} else {
+			ls.NonFreeSegments++
+			ls.TotalNonFreeSegmentBytes += s.SizeBytes
+		}
+	}
+	return &ls, nil
+}
+
+func (f *Fake) ReclaimLog() (*hanautil.ReclaimResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ReclaimLog"); err != nil {
+		return nil, err
+	}
+
+	rr := hanautil.ReclaimResult{Services: make([]hanautil.ServiceLogReclaim, 0), BeforeTime: f.now(), AfterTime: f.now()}
+	services := make(map[string]int)
+	kept := make([]LogSegment, 0, len(f.LogSegments))
+	for _, s := range f.LogSegments {
+		key := fmt.Sprintf("%s:%d", s.Hostname, s.Port)
+		i, ok := services[key]
+		if !ok {
+			i = len(rr.Services)
+			services[key] = i
+			rr.Services = append(rr.Services, hanautil.ServiceLogReclaim{Hostname: s.Hostname, Port: s.Port, ServiceName: s.ServiceName})
+		}
+		if !s.Free {
+			kept = append(kept, s)
+			continue
+		}
+		rr.Services[i].FreeSegmentsBefore++
+		rr.Services[i].FreeSegmentBytesBefore += s.SizeBytes
+	}
+	f.LogSegments = kept
+	return &rr, nil
+}
+
+func (f *Fake) GetDiskUsage() ([]hanautil.DiskUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetDiskUsage"); err != nil {
+		return nil, err
+	}
+	return append(make([]hanautil.DiskUsage, 0), f.DiskUsage...), nil
+}
+
+func (f *Fake) GetVolumeUsage() ([]hanautil.VolumeUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetVolumeUsage"); err != nil {
+		return nil, err
+	}
+	return append(make([]hanautil.VolumeUsage, 0), f.VolumeUsage...), nil
+}
+
+func (f *Fake) GetMemoryUsage(topN uint) (*hanautil.MemoryUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetMemoryUsage"); err != nil {
+		return nil, err
+	}
+	mu := hanautil.MemoryUsage{
+		Hosts:             append(make([]hanautil.HostMemory, 0), f.MemoryUsage.Hosts...),
+		Services:          append(make([]hanautil.ServiceMemory, 0), f.MemoryUsage.Services...),
+		TopHeapAllocators: make([]hanautil.HeapAllocator, 0),
+	}
+	heap := f.MemoryUsage.TopHeapAllocators
+	if uint(len(heap)) > topN {
+		heap = heap[:topN]
+	}
+	mu.TopHeapAllocators = append(mu.TopHeapAllocators, heap...)
+	return &mu, nil
+}
+
+func (f *Fake) GetTableSizes(filter hanautil.TableSizeFilter) ([]hanautil.TableSize, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetTableSizes"); err != nil {
+		return nil, err
+	}
+	switch filter.StoreType {
+	case "", "COLUMN", "ROW":
+	default:
+		return nil, fmt.Errorf("InvalidStoreType")
+	}
+	ts := make([]hanautil.TableSize, 0)
+	for _, t := range f.Tables {
+		if (filter.SchemaName == "" || t.SchemaName == filter.SchemaName) &&
+			(filter.StoreType == "" || t.StoreType == filter.StoreType) {
+			ts = append(ts, t)
+		}
+	}
+	sort.SliceStable(ts, func(i, j int) bool { return ts[i].MemorySizeBytes > ts[j].MemorySizeBytes })
+	if filter.Limit > 0 && uint(len(ts)) > filter.Limit {
+		ts = ts[:filter.Limit]
+	}
+	return ts, nil
+}
+
+func (f *Fake) GetTableUnloads(days uint) ([]hanautil.TableUnload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetTableUnloads"); err != nil {
+		return nil, err
+	}
+	cutoff := f.cutoff(days)
+	tu := make([]hanautil.TableUnload, 0)
+	for _, u := range f.TableUnloads {
+		if u.UnloadTime.After(cutoff) {
+			tu = append(tu, u)
+		}
+	}
+	return tu, nil
+}
+
+func (f *Fake) GetDeltaMergeCandidates(thresholds hanautil.DeltaMergeThresholds) ([]hanautil.DeltaMergeCandidate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetDeltaMergeCandidates"); err != nil {
+		return nil, err
+	}
+	dc := make([]hanautil.DeltaMergeCandidate, 0)
+	for _, c := range f.DeltaMergeCandidates {
+		if c.DeltaSizeBytes >= thresholds.MinDeltaBytes && c.DeltaRecordCount >= thresholds.MinDeltaRecords {
+			dc = append(dc, c)
+		}
+	}
+	return dc, nil
+}
+
+// MergeDelta merges a table found in Tables with the store type COLUMN. The
+// delta of the table and of any matching DeltaMergeCandidates is set to 0.
+func (f *Fake) MergeDelta(schema, table string) (*hanautil.MergeStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("MergeDelta"); err != nil {
+		return nil, err
+	}
+	for i, t := range f.Tables {
+		if t.SchemaName != schema || t.TableName != table || t.StoreType != "COLUMN" {
+			continue
+		}
+		ms := hanautil.MergeStats{BytesMerged: t.DeltaSizeBytes}
+		f.Tables[i].DeltaSizeBytes = 0
+		for j, c := range f.DeltaMergeCandidates {
+			if c.SchemaName == schema && c.TableName == table {
+				ms.RecordsMerged = c.DeltaRecordCount
+				f.DeltaMergeCandidates[j].MainSizeBytes += c.DeltaSizeBytes
+				f.DeltaMergeCandidates[j].DeltaSizeBytes = 0
+				f.DeltaMergeCandidates[j].DeltaRecordCount = 0
+				f.DeltaMergeCandidates[j].LastMergeTime = f.now()
+			}
+		}
+		return &ms, nil
+	}
+	return nil, fmt.Errorf("TableNotFound")
+}
+
+func (f *Fake) GetStatisticsHistorySizes() ([]hanautil.StatisticsHistoryTable, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetStatisticsHistorySizes"); err != nil {
+		return nil, err
+	}
+	return append(make([]hanautil.StatisticsHistoryTable, 0), f.StatisticsHistory...), nil
+}
+
+// PurgeStatisticsHistory reports each table of StatisticsHistory. The Fake does
+// not hold the age of history rows, so no rows are removed.
+func (f *Fake) PurgeStatisticsHistory(days uint) ([]hanautil.StatisticsHistoryPurge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("PurgeStatisticsHistory"); err != nil {
+		return nil, err
+	}
+	purged := make([]hanautil.StatisticsHistoryPurge, 0, len(f.StatisticsHistory))
+	for _, t := range f.StatisticsHistory {
+		purged = append(purged, hanautil.StatisticsHistoryPurge{
+			TableName:   t.TableName,
+			RowsBefore:  t.RecordCount,
+			RowsAfter:   t.RecordCount,
+			BytesBefore: t.TableSizeBytes,
+			BytesAfter:  t.TableSizeBytes,
+		})
+	}
+	return purged, nil
+}
+
+func (f *Fake) GetAuditLogStats() (*hanautil.AuditLogStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetAuditLogStats"); err != nil {
+		return nil, err
+	}
+	as := hanautil.AuditLogStats{Entries: uint64(len(f.AuditLog))}
+	for _, e := range f.AuditLog {
+		if as.OldestEntry.IsZero() || e.Before(as.OldestEntry) {
+			as.OldestEntry = e
+		}
+		if e.After(as.NewestEntry) {
+			as.NewestEntry = e
+		}
+	}
+	return &as, nil
+}
+
+func (f *Fake) TruncateAuditLog(before time.Time) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("TruncateAuditLog"); err != nil {
+		return 0, err
+	}
+	kept := make([]time.Time, 0, len(f.AuditLog))
+	for _, e := range f.AuditLog {
+		if !e.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	removed := uint64(len(f.AuditLog) - len(kept))
+	f.AuditLog = kept
+	return removed, nil
+}
+
+func (f *Fake) GetSqlPlanCacheStats() (*hanautil.SqlPlanCacheStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetSqlPlanCacheStats"); err != nil {
+		return nil, err
+	}
+	ps := f.SqlPlanCache
+	return &ps, nil
+}
+
+func (f *Fake) ClearSqlPlanCache() (*hanautil.ClearStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ClearSqlPlanCache"); err != nil {
+		return nil, err
+	}
+	cs := hanautil.ClearStats{EntriesRemoved: f.SqlPlanCache.CachedPlans, BytesRemoved: f.SqlPlanCache.CachedPlanBytes}
+	f.SqlPlanCache.CachedPlans = 0
+	f.SqlPlanCache.CachedPlanBytes = 0
+	return &cs, nil
+}
+
+func (f *Fake) GetExpensiveStatementsStats() (*hanautil.ExpensiveStatementsStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("GetExpensiveStatementsStats"); err != nil {
+		return nil, err
+	}
+	es := f.ExpensiveStatements
+	return &es, nil
+}
+
+func (f *Fake) ClearExpensiveStatementsTrace() (*hanautil.ClearStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail("ClearExpensiveStatementsTrace"); err != nil {
+		return nil, err
+	}
+	cs := hanautil.ClearStats{EntriesRemoved: f.ExpensiveStatements.Entries, BytesRemoved: f.ExpensiveStatements.MemoryBytes}
+	f.ExpensiveStatements = hanautil.ExpensiveStatementsStats{}
+	return &cs, nil
+}
+
+func (f *Fake) PlanHousekeeping(policy hanautil.HousekeepingPolicy) (*hanautil.HousekeepingPlan, error) {
+	f.mu.Lock()
+	err := f.fail("PlanHousekeeping")
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return hanautil.BuildHousekeepingPlan(f, policy)
+}
+
+func (f *Fake) ApplyHousekeeping(plan *hanautil.HousekeepingPlan) (*hanautil.HousekeepingReport, error) {
+	f.mu.Lock()
+	err := f.fail("ApplyHousekeeping")
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return hanautil.RunHousekeepingPlan(f, plan)
+}
@@ -0,0 +1,351 @@
+package hanautiltest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+var genTime = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newFake returns a Fake holding two weeks of daily full backups with hourly
+// log backups, old and new trace files, alerts and log segments
+func newFake() *Fake {
+	f := &Fake{Now: genTime, BackupCatalogSizeBytes: 512}
+	var id uint64 = 1000
+	for d := 14; d > 0; d-- {
+		day := genTime.AddDate(0, 0, -d)
+		id++
+		f.Backups = append(f.Backups, Backup{ID: id, Type: BackupFull, Start: day, SizeBytes: 1024})
+		for h := 1; h < 24; h++ {
+			id++
+			f.Backups = append(f.Backups, Backup{ID: id, Type: BackupLog, Start: day.Add(time.Duration(h) * time.Hour), SizeBytes: 10})
+		}
+	}
+	f.TraceFiles = []TraceFile{
+		{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "old.trc", FileSizeBytes: 100, LastModified: genTime.AddDate(0, 0, -30)}},
+		{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "open.trc", FileSizeBytes: 200, LastModified: genTime.AddDate(0, 0, -30)}, Open: true},
+		{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "new.trc", FileSizeBytes: 300, LastModified: genTime}},
+	}
+	for i := 0; i < 25; i++ {
+		f.Alerts = append(f.Alerts, hanautil.StatServerAlert{AlertID: uint(i % 3), Rating: uint(i%5 + 1), Timestamp: genTime.AddDate(0, 0, -i*4)})
+	}
+	f.LogSegments = []LogSegment{
+		{Hostname: "hana01", Port: 30003, ServiceName: "indexserver", Free: true, SizeBytes: 1024},
+		{Hostname: "hana01", Port: 30003, ServiceName: "indexserver", Free: false, SizeBytes: 1024},
+		{Hostname: "hana01", Port: 30001, ServiceName: "nameserver", Free: true, SizeBytes: 64},
+	}
+	return f
+}
+
+func TestFake_TruncateBackupCatalog(t *testing.T) {
+	f := newFake()
+	before, err := f.GetBackupSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.BackupCatalogEntries != 14*24 {
+		t.Fatalf("BackupCatalogEntries = %d, want %d", before.BackupCatalogEntries, 14*24)
+	}
+
+	id, err := f.GetFullBackupId(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	predicted, err := f.GetBackupFilesBeforeBackupID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := f.TruncateBackupCatalog(7, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := f.GetBackupSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.FilesRemoved != predicted.Files {
+		t.Errorf("FilesRemoved = %d, want %d", ts.FilesRemoved, predicted.Files)
+	}
+	if ts.BytesRemoved != predicted.Bytes {
+		t.Errorf("BytesRemoved = %d, want %d", ts.BytesRemoved, predicted.Bytes)
+	}
+	if after.BackupCatalogEntries != before.BackupCatalogEntries-ts.FilesRemoved {
+		t.Errorf("BackupCatalogEntries after = %d, want %d", after.BackupCatalogEntries, before.BackupCatalogEntries-ts.FilesRemoved)
+	}
+	if !after.OldestFullBackupDate.Equal(genTime.AddDate(0, 0, -8)) {
+		t.Errorf("OldestFullBackupDate = %v, want %v", after.OldestFullBackupDate, genTime.AddDate(0, 0, -8))
+	}
+
+	_, err = f.TruncateBackupCatalog(30, false)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("TruncateBackupCatalog() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestFake_GetTraceFiles(t *testing.T) {
+	f := newFake()
+	f.TraceFiles = append(f.TraceFiles,
+		TraceFile{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "old.trc.gz", FileSizeBytes: 10, LastModified: genTime.AddDate(0, 0, -30)}},
+		TraceFile{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "old.log", FileSizeBytes: 10, LastModified: genTime.AddDate(0, 0, -30)}})
+	tf, err := f.GetTraceFiles(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, t := range tf {
+		names = append(names, t.FileName)
+	}
+	if want := []string{"old.trc", "open.trc", "old.trc.gz"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetTraceFiles() = %v, want %v", names, want)
+	}
+}
+
+func TestFake_RemoveTraceFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{"Good", "old.trc", ""},
+		{"Open", "open.trc", "TraceFileNotRemoved"},
+		{"NotFound", "missing.trc", "TraceFileNotFound"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFake()
+			err := f.RemoveTraceFile("hana01", tt.file)
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("RemoveTraceFile() error = %v, want %v", err, tt.wantErr)
+			}
+			tf, _ := f.GetTraceFiles(7)
+			want := 2
+			if tt.wantErr == "" {
+				want = 1
+			}
+			if len(tf) != want {
+				t.Errorf("GetTraceFiles() = %d files, want %d", len(tf), want)
+			}
+		})
+	}
+}
+
+func TestFake_RemoveStatServerAlertsBatched(t *testing.T) {
+	f := newFake()
+	count, _ := f.GetStatServerAlerts(42)
+	var batches []uint64
+	bs, err := f.RemoveStatServerAlertsBatched(context.Background(), 42, hanautil.BatchOptions{
+		BatchSize: 5,
+		Progress:  func(p hanautil.BatchProgress) { batches = append(batches, p.RowsRemoved) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.TotalRemoved != uint64(count) || len(batches) != len(bs.RowsPerBatch) {
+		t.Errorf("RemoveStatServerAlertsBatched() = %v, want %d removed", bs, count)
+	}
+	if left, _ := f.GetStatServerAlerts(42); left != 0 {
+		t.Errorf("GetStatServerAlerts() = %d after removal, want 0", left)
+	}
+	if len(f.Alerts) != 25-int(count) {
+		t.Errorf("newer alerts were removed, %d left", len(f.Alerts))
+	}
+}
+
+func TestFake_ReclaimLog(t *testing.T) {
+	f := newFake()
+	rr, err := f.ReclaimLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.BytesReclaimed() != 1088 || rr.SegmentsReclaimed() != 2 || len(rr.Services) != 2 {
+		t.Errorf("ReclaimLog() = %+v", rr)
+	}
+	ls, _ := f.GetLogSegmentStats()
+	if ls.FreeSegments != 0 || ls.NonFreeSegments != 1 {
+		t.Errorf("GetLogSegmentStats() = %+v after reclaim", ls)
+	}
+}
+
+func TestFake_Housekeeping(t *testing.T) {
+	f := newFake()
+	plan, err := f.PlanHousekeeping(hanautil.HousekeepingPolicy{
+		TraceFiles: true, TraceRetentionDays: 7,
+		BackupCatalog: true, BackupRetentionDays: 7,
+		StatServerAlerts: true, AlertRetentionDays: 42,
+		ReclaimLog: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := f.ApplyHousekeeping(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.EntriesRemoved() != plan.PredictedEntries()-1 {
+		/*The open trace file cannot be removed*/
+		t.Errorf("EntriesRemoved() = %d, want %d", report.EntriesRemoved(), plan.PredictedEntries()-1)
+	}
+	if report.Steps[0].Failed != 1 {
+		t.Errorf("trace file step Failed = %d, want 1", report.Steps[0].Failed)
+	}
+
+	replan, err := f.PlanHousekeeping(plan.Policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range replan.Steps[1:] {
+		if !s.Skip {
+			t.Errorf("step %s not skipped after housekeeping: %s", s.Type, s.Description)
+		}
+	}
+}
+
+func TestFake_HousekeepingPlanRoundTrip(t *testing.T) {
+	f := newFake()
+	plan, err := f.PlanHousekeeping(hanautil.HousekeepingPolicy{
+		TraceFiles: true, TraceRetentionDays: 7,
+		BackupCatalog: true, BackupRetentionDays: 7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	/*A plan sent to a REST client and back keeps what it found*/
+	b, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got hanautil.HousekeepingPlan
+	err = json.Unmarshal(b, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, plan) {
+		t.Fatalf("plan after JSON round trip = %+v, want %+v", got, *plan)
+	}
+
+	/*Files and backups that only become old after planning are kept*/
+	f.Now = genTime.AddDate(0, 0, 2)
+	f.TraceFiles = append(f.TraceFiles, TraceFile{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "later.trc", FileSizeBytes: 400, LastModified: genTime.AddDate(0, 0, -30)}})
+	report, err := f.ApplyHousekeeping(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Steps[0].EntriesRemoved+report.Steps[0].Failed != plan.Steps[0].PredictedEntries {
+		t.Errorf("trace file step removed %d and failed %d, want %d", report.Steps[0].EntriesRemoved, report.Steps[0].Failed, plan.Steps[0].PredictedEntries)
+	}
+	if report.Steps[1].EntriesRemoved != plan.Steps[1].PredictedEntries {
+		t.Errorf("backup catalog step removed %d, want %d", report.Steps[1].EntriesRemoved, plan.Steps[1].PredictedEntries)
+	}
+	if !slices.ContainsFunc(f.TraceFiles, func(t TraceFile) bool { return t.FileName == "later.trc" }) {
+		t.Errorf("trace file found after planning was removed")
+	}
+}
+
+func TestFake_Errors(t *testing.T) {
+	f := newFake()
+	f.Errors = map[string]error{"TruncateBackupCatalog": fmt.Errorf("DbError")}
+	_, err := f.TruncateBackupCatalog(7, false)
+	if err == nil || err.Error() != "DbError" {
+		t.Errorf("TruncateBackupCatalog() error = %v, want DbError", err)
+	}
+	if bs, _ := f.GetBackupSummary(); bs.BackupCatalogEntries != 14*24 {
+		t.Errorf("catalog changed by a failed truncation")
+	}
+
+	f.Close()
+	if _, err := f.GetVersion(); !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("GetVersion() error = %v after Close, want %v", err, sql.ErrConnDone)
+	}
+	f.Connect()
+	if _, err := f.GetVersion(); err != nil {
+		t.Errorf("GetVersion() error = %v after Connect", err)
+	}
+}
+
+func TestFake_GetBackupSummaryFiltered(t *testing.T) {
+	f := newFake()
+	bs, err := f.GetBackupSummaryFiltered(hanautil.BackupFilter{From: genTime.AddDate(0, 0, -7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.FullBackups != 7 || bs.LogBackups != 7*23 {
+		t.Errorf("summary has %d full and %d log backups, want 7 and %d", bs.FullBackups, bs.LogBackups, 7*23)
+	}
+	if !bs.FullBackupDates.Oldest.Equal(genTime.AddDate(0, 0, -7)) || bs.FullBackupDates.OldestAgeSeconds != 7*86400 {
+		t.Errorf("unexpected full backup dates %+v", bs.FullBackupDates)
+	}
+	if !bs.LogBackupDates.Newest.Equal(genTime.Add(-time.Hour)) || bs.LogBackupDates.NewestAgeSeconds != 3600 {
+		t.Errorf("unexpected log backup dates %+v", bs.LogBackupDates)
+	}
+
+	if bs.SizeOfBackupCatalog != 512 {
+		t.Errorf("SizeOfBackupCatalog = %d, want 512", bs.SizeOfBackupCatalog)
+	}
+
+	/*The catalog size is that of the newest successful entry selected*/
+	bs, err = f.GetBackupSummaryFiltered(hanautil.BackupFilter{To: genTime.AddDate(0, 0, -7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.SizeOfBackupCatalog != 0 {
+		t.Errorf("SizeOfBackupCatalog before the newest entry = %d, want 0", bs.SizeOfBackupCatalog)
+	}
+	for i := range f.Backups {
+		f.Backups[i].CatalogSizeBytes = f.Backups[i].ID
+	}
+	f.Backups[len(f.Backups)-1].Failed = true
+	bs, err = f.GetBackupSummaryFiltered(hanautil.BackupFilter{To: genTime.AddDate(0, 0, -7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1000 + uint64(7*24); bs.SizeOfBackupCatalog != want {
+		t.Errorf("SizeOfBackupCatalog = %d, want %d", bs.SizeOfBackupCatalog, want)
+	}
+	bs, err = f.GetBackupSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1000 + uint64(14*24) - 1; bs.SizeOfBackupCatalog != want {
+		t.Errorf("SizeOfBackupCatalog = %d, want %d", bs.SizeOfBackupCatalog, want)
+	}
+
+	_, err = f.GetBackupSummaryFiltered(hanautil.BackupFilter{BeforeBackupID: "latest"})
+	if err == nil {
+		t.Errorf("GetBackupSummaryFiltered() with an invalid backup ID returned no error")
+	}
+}
+
+func TestFake_GetBackupSummaryUnknownType(t *testing.T) {
+	f := newFake()
+	f.Backups = append(f.Backups, Backup{ID: 9999, Type: "tape backup", Start: genTime.Add(-time.Hour), SizeBytes: 4096})
+	bs, err := f.GetBackupSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tape := bs.Types["tape backup"]
+	if tape.Count != 1 || tape.SizeBytes != 4096 || tape.Dates.NewestAgeSeconds != 3600 {
+		t.Errorf("unexpected statistics for the unknown type %+v", tape)
+	}
+	if bs.Types[BackupFull].Count != bs.FullBackups {
+		t.Errorf("Types holds %d full backups, want %d", bs.Types[BackupFull].Count, bs.FullBackups)
+	}
+	if got, want := bs.GetAllBytes(), uint64(14*1024+14*23*10+4096+512); got != want {
+		t.Errorf("GetAllBytes() = %d, want %d", got, want)
+	}
+
+	f.StrictBackupTypes = true
+	_, err = f.GetBackupSummary()
+	if err == nil || err.Error() != "UnexpectedBackupType" {
+		t.Errorf("GetBackupSummary() in strict mode returned error %v, want UnexpectedBackupType", err)
+	}
+}
@@ -0,0 +1,319 @@
+package hanautil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+/******************************************************************************/
+/* This file contains the housekeeping subsystem. A HousekeepingPlan is built */
+/* from a HousekeepingPolicy without changing the database, shown to the      */
+/* operator and then applied step by step.                                    */
+/******************************************************************************/
+
+// HousekeepingStepType identifies the area of the database a housekeeping step
+// acts upon
+type HousekeepingStepType string
+
+const (
+	StepTraceFiles       HousekeepingStepType = "trace_files"
+	StepBackupCatalog    HousekeepingStepType = "backup_catalog"
+	StepStatServerAlerts HousekeepingStepType = "stat_server_alerts"
+	StepReclaimLog       HousekeepingStepType = "reclaim_log"
+)
+
+// HousekeepingPolicy sets which areas are cleaned by housekeeping and how many
+// days of data are retained in each area. Complete sets whether backup files
+// removed from the catalog are also destroyed, see TruncateBackupCatalog.
+type HousekeepingPolicy struct {
+	TraceFiles          bool `json:"trace_files" yaml:"trace_files"`
+	TraceRetentionDays  uint `json:"trace_retention_days" yaml:"trace_retention_days"`
+	BackupCatalog       bool `json:"backup_catalog" yaml:"backup_catalog"`
+	BackupRetentionDays uint `json:"backup_retention_days" yaml:"backup_retention_days"`
+	Complete            bool `json:"complete" yaml:"complete"`
+	StatServerAlerts    bool `json:"stat_server_alerts" yaml:"stat_server_alerts"`
+	AlertRetentionDays  uint `json:"alert_retention_days" yaml:"alert_retention_days"`
+	ReclaimLog          bool `json:"reclaim_log" yaml:"reclaim_log"`
+}
+
+// HousekeepingStep is a single step of a HousekeepingPlan along with the
+// number of entries and bytes it is predicted to remove. The entries of the
+// backup catalog step are backup files, as reported by TruncateBackupCatalog.
+// A step is skipped when there is nothing for it to do, Description explains
+// why. TraceFiles holds the files the trace file step will remove and BackupID
+// the backup the backup catalog step will truncate before, both found when the
+// plan was built.
+type HousekeepingStep struct {
+	Type             HousekeepingStepType `json:"type" yaml:"type"`
+	Description      string               `json:"description" yaml:"description"`
+	PredictedEntries uint64               `json:"predicted_entries" yaml:"predicted_entries"`
+	PredictedBytes   uint64               `json:"predicted_bytes" yaml:"predicted_bytes"`
+	Skip             bool                 `json:"skip" yaml:"skip"`
+	TraceFiles       []TraceFile          `json:"trace_files,omitempty" yaml:"trace_files,omitempty"`
+	BackupID         string               `json:"backup_id,omitempty" yaml:"backup_id,omitempty"`
+}
+
+// HousekeepingPlan holds the steps that will be performed when the plan is
+// applied with ApplyHousekeeping
+type HousekeepingPlan struct {
+	Policy HousekeepingPolicy `json:"policy" yaml:"policy"`
+	Steps  []HousekeepingStep `json:"steps" yaml:"steps"`
+}
+
+// PredictedEntries returns the total number of entries the plan is predicted
+// to remove
+func (hp *HousekeepingPlan) PredictedEntries() uint64 {
+	var total uint64
+	for _, s := range hp.Steps {
+		total += s.PredictedEntries
+	}
+	return total
+}
+
+// PredictedBytes returns the total number of bytes the plan is predicted to
+// remove
+func (hp *HousekeepingPlan) PredictedBytes() uint64 {
+	var total uint64
+	for _, s := range hp.Steps {
+		total += s.PredictedBytes
+	}
+	return total
+}
+
+// HousekeepingStepResult provides the number of entries and bytes removed by
+// a single housekeeping step. For the trace file step, Failed holds the number
+// of trace files that could not be removed, usually because they are open.
+type HousekeepingStepResult struct {
+	Type           HousekeepingStepType `json:"type" yaml:"type"`
+	EntriesRemoved uint64               `json:"entries_removed" yaml:"entries_removed"`
+	BytesRemoved   uint64               `json:"bytes_removed" yaml:"bytes_removed"`
+	Failed         uint64               `json:"failed" yaml:"failed"`
+	Skipped        bool                 `json:"skipped" yaml:"skipped"`
+}
+
+// HousekeepingReport provides the results of each step of an applied
+// HousekeepingPlan
+type HousekeepingReport struct {
+	Steps []HousekeepingStepResult `json:"steps" yaml:"steps"`
+}
+
+// EntriesRemoved returns the total number of entries removed by all steps
+func (hr *HousekeepingReport) EntriesRemoved() uint64 {
+	var total uint64
+	for _, s := range hr.Steps {
+		total += s.EntriesRemoved
+	}
+	return total
+}
+
+// BytesRemoved returns the total number of bytes removed by all steps
+func (hr *HousekeepingReport) BytesRemoved() uint64 {
+	var total uint64
+	for _, s := range hr.Steps {
+		total += s.BytesRemoved
+	}
+	return total
+}
+
+// PlanHousekeeping builds a HousekeepingPlan from the given 'policy'. Building
+// a plan does not change the database, it only gathers what each step is
+// predicted to remove so that it can be reviewed before ApplyHousekeeping is
+// called. Backup bytes are only predicted when the policy is Complete, as
+// otherwise no backup files are destroyed.
+func (h *HanaUtilClient) PlanHousekeeping(policy HousekeepingPolicy) (*HousekeepingPlan, error) {
+	ctx, span := h.startSpan(context.Background(), "PlanHousekeeping")
+	defer span.End()
+
+	/*Each operation of the plan is a child span of this one*/
+	hp, err := BuildHousekeepingPlan(h.withParent(ctx), policy)
+	if err != nil {
+		/*PromoteError*/
+		return nil, spanError(ctx, span, err)
+	}
+	return hp, nil
+}
+
+// BuildHousekeepingPlan builds a HousekeepingPlan from the given 'policy' using
+// the operations of 'c'. It allows implementations of Client, such as fakes,
+// to provide PlanHousekeeping with the same behaviour as HanaUtilClient.
+func BuildHousekeepingPlan(c Client, policy HousekeepingPolicy) (*HousekeepingPlan, error) {
+	hp := HousekeepingPlan{Policy: policy, Steps: make([]HousekeepingStep, 0)}
+
+	if policy.TraceFiles {
+		tf, err := c.GetTraceFiles(policy.TraceRetentionDays)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		step := HousekeepingStep{
+			Type:             StepTraceFiles,
+			Description:      fmt.Sprintf("remove %d trace files older than %d days", len(tf), policy.TraceRetentionDays),
+			PredictedEntries: uint64(len(tf)),
+			Skip:             len(tf) == 0,
+			TraceFiles:       tf,
+		}
+		for _, t := range tf {
+			step.PredictedBytes += t.FileSizeBytes
+		}
+		hp.Steps = append(hp.Steps, step)
+	}
+
+	if policy.BackupCatalog {
+		step := HousekeepingStep{Type: StepBackupCatalog}
+		id, err := c.GetFullBackupId(int(policy.BackupRetentionDays))
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			step.Description = fmt.Sprintf("no successful full backup older than %d days", policy.BackupRetentionDays)
+			step.Skip = true
+		case err != nil:
+			/*PromoteError*/
+			return nil, err
+		default:
+			/*Files are counted as TruncateBackupCatalog counts them*/
+			fs, err := c.GetBackupFilesBeforeBackupID(id)
+			if err != nil {
+				/*PromoteError*/
+				return nil, err
+			}
+			step.Description = fmt.Sprintf("remove %d backup files from the catalog before backup ID %s", fs.Files, id)
+			step.BackupID = id
+			step.PredictedEntries = fs.Files
+			step.Skip = fs.Files == 0
+			if policy.Complete {
+				step.Description += " and destroy them"
+				step.PredictedBytes = fs.Bytes
+			}
+		}
+		hp.Steps = append(hp.Steps, step)
+	}
+
+	if policy.StatServerAlerts {
+		alerts, err := c.GetStatServerAlerts(policy.AlertRetentionDays)
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		hp.Steps = append(hp.Steps, HousekeepingStep{
+			Type:             StepStatServerAlerts,
+			Description:      fmt.Sprintf("remove %d statistics server alerts older than %d days", alerts, policy.AlertRetentionDays),
+			PredictedEntries: uint64(alerts),
+			Skip:             alerts == 0,
+		})
+	}
+
+	if policy.ReclaimLog {
+		ls, err := c.GetLogSegmentStats()
+		if err != nil {
+			/*PromoteError*/
+			return nil, err
+		}
+		hp.Steps = append(hp.Steps, HousekeepingStep{
+			Type:             StepReclaimLog,
+			Description:      fmt.Sprintf("reclaim %d free log segments", ls.FreeSegments),
+			PredictedEntries: ls.FreeSegments,
+			PredictedBytes:   ls.TotalFreeSegmentBytes,
+			Skip:             ls.FreeSegments == 0,
+		})
+	}
+
+	return &hp, nil
+}
+
+// ApplyHousekeeping performs each of the steps of the given 'plan' in order.
+// Skipped steps are reported but not performed. The trace file step only
+// removes the files found when the plan was built, files that cannot be
+// removed are counted as failed rather than stopping housekeeping. The backup
+// catalog step is truncated before the full backup found when the plan was
+// built, even if newer full backups have been taken since.
+//
+// The function returns a pointer to the type `HousekeepingReport` and an
+// error. If a step fails, housekeeping stops and the report holds the results
+// of the steps completed before the failure.
+func (h *HanaUtilClient) ApplyHousekeeping(plan *HousekeepingPlan) (*HousekeepingReport, error) {
+	ctx, span := h.startSpan(context.Background(), "ApplyHousekeeping")
+	defer span.End()
+
+	/*Each step is a child span of this one*/
+	hr, err := RunHousekeepingPlan(h.withParent(ctx), plan)
+	if err != nil {
+		/*PromoteError*/
+		return hr, spanError(ctx, span, err)
+	}
+	return hr, nil
+}
+
+// RunHousekeepingPlan performs each of the steps of the given 'plan' using the
+// operations of 'c'. It allows implementations of Client, such as fakes, to
+// provide ApplyHousekeeping with the same behaviour as HanaUtilClient. If the
+// plan was not built by BuildHousekeepingPlan or PlanHousekeeping, the trace
+// files removed are those found when the step is applied and the backup
+// catalog is truncated relative to the latest full backup at that time.
+func RunHousekeepingPlan(c Client, plan *HousekeepingPlan) (*HousekeepingReport, error) {
+	hr := HousekeepingReport{Steps: make([]HousekeepingStepResult, 0)}
+
+	for _, step := range plan.Steps {
+		res := HousekeepingStepResult{Type: step.Type, Skipped: step.Skip}
+		if step.Skip {
+			hr.Steps = append(hr.Steps, res)
+			continue
+		}
+
+		switch step.Type {
+		case StepTraceFiles:
+			tf := step.TraceFiles
+			if tf == nil {
+				var err error
+				tf, err = c.GetTraceFiles(plan.Policy.TraceRetentionDays)
+				if err != nil {
+					/*PromoteError*/
+					return &hr, err
+				}
+			}
+			for _, t := range tf {
+				err := c.RemoveTraceFile(t.Hostname, t.FileName)
+				if err != nil {
+					res.Failed++
+					continue
+				}
+				res.EntriesRemoved++
+				res.BytesRemoved += t.FileSizeBytes
+			}
+		case StepBackupCatalog:
+			var ts *TruncateStats
+			var err error
+			if step.BackupID != "" {
+				ts, err = c.TruncateBackupCatalogBeforeBackupID(step.BackupID, plan.Policy.Complete)
+			} else {
+				ts, err = c.TruncateBackupCatalog(int(plan.Policy.BackupRetentionDays), plan.Policy.Complete)
+			}
+			if err != nil {
+				/*PromoteError*/
+				return &hr, err
+			}
+			res.EntriesRemoved = ts.FilesRemoved
+			res.BytesRemoved = ts.BytesRemoved
+		case StepStatServerAlerts:
+			removed, err := c.RemoveStatServerAlerts(plan.Policy.AlertRetentionDays)
+			if err != nil {
+				/*PromoteError*/
+				return &hr, err
+			}
+			res.EntriesRemoved = removed
+		case StepReclaimLog:
+			rr, err := c.ReclaimLog()
+			if err != nil {
+				/*PromoteError*/
+				return &hr, err
+			}
+			res.EntriesRemoved = rr.SegmentsReclaimed()
+			res.BytesRemoved = rr.BytesReclaimed()
+		default:
+			return &hr, fmt.Errorf("UnexpectedStepType")
+		}
+		hr.Steps = append(hr.Steps, res)
+	}
+
+	return &hr, nil
+}
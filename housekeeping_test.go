@@ -0,0 +1,240 @@
+package hanautil
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestHanaUtilClient_PlanHousekeeping(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	tf := []TraceFile{
+		{"hana01", "nameserver_hana01.30001.000.trc", 64000, genTime},
+		{"hana01", "indexserver_hana01.30003.000.trc", 128000, genTime}}
+	allPolicy := HousekeepingPolicy{true, 7, true, 28, true, true, 42, true}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		policy HousekeepingPolicy
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *HousekeepingPlan
+		wantErr bool
+	}{
+		{"GoodAll", fields{db1, ""}, args{allPolicy}, &HousekeepingPlan{allPolicy, []HousekeepingStep{
+			{StepTraceFiles, "remove 2 trace files older than 7 days", 2, 192000, false, tf, ""},
+			{StepBackupCatalog, "remove 100 backup files from the catalog before backup ID 123 and destroy them", 100, 1024000, false, nil, "123"},
+			{StepStatServerAlerts, "remove 99 statistics server alerts older than 42 days", 99, 0, false, nil, ""},
+			{StepReclaimLog, "reclaim 10 free log segments", 10, 10240, false, nil, ""}}}, false},
+		{"GoodNothingToDo", fields{db1, ""}, args{HousekeepingPolicy{BackupCatalog: true, BackupRetentionDays: 28, ReclaimLog: true}},
+			&HousekeepingPlan{HousekeepingPolicy{BackupCatalog: true, BackupRetentionDays: 28, ReclaimLog: true}, []HousekeepingStep{
+				{StepBackupCatalog, "no successful full backup older than 28 days", 0, 0, true, nil, ""},
+				{StepReclaimLog, "reclaim 0 free log segments", 0, 0, true, nil, ""}}}, false},
+		{"GoodNoPolicy", fields{db1, ""}, args{HousekeepingPolicy{}}, &HousekeepingPlan{HousekeepingPolicy{}, []HousekeepingStep{}}, false},
+		{"TraceFilesDbError", fields{db1, ""}, args{HousekeepingPolicy{TraceFiles: true, TraceRetentionDays: 7}}, nil, true},
+		{"BackupIdDbError", fields{db1, ""}, args{HousekeepingPolicy{BackupCatalog: true, BackupRetentionDays: 28}}, nil, true},
+		{"BackupFilesDbError", fields{db1, ""}, args{HousekeepingPolicy{BackupCatalog: true, BackupRetentionDays: 28}}, nil, true},
+		{"AlertsDbError", fields{db1, ""}, args{HousekeepingPolicy{StatServerAlerts: true, AlertRetentionDays: 42}}, nil, true},
+		{"LogDbError", fields{db1, ""}, args{HousekeepingPolicy{ReclaimLog: true}}, nil, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "GoodAll":
+			rows1 := mock.NewRows([]string{"HOST", "FILE_NAME", "FILE_SIZE", "FILE_MTIME"})
+			rows1.AddRow("hana01", "nameserver_hana01.30001.000.trc", 64000, genTime)
+			rows1.AddRow("hana01", "indexserver_hana01.30003.000.trc", 128000, genTime)
+			rows2 := mock.NewRows([]string{"BACKUP_ID"}).AddRow("123")
+			rows3 := mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow(100, 1024000)
+			rows4 := mock.NewRows([]string{"COUNT"}).AddRow(99)
+			rows5 := mock.NewRows([]string{"STATE", "SEGMENTS", "BYTES"})
+			rows5.AddRow("Free", 10, 10240)
+			rows5.AddRow("NonFree", 50, 51200)
+			mock.ExpectQuery(f_GetTraceFiles(7)).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetLatestFullBackupID(28)).WillReturnRows(rows2)
+			mock.ExpectQuery(f_GetTruncateData("123")).WillReturnRows(rows3)
+			mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnRows(rows4)
+			mock.ExpectQuery(q_GetLogSegmentStats).WillReturnRows(rows5)
+		case "GoodNothingToDo":
+			rows1 := mock.NewRows([]string{"STATE", "SEGMENTS", "BYTES"})
+			rows1.AddRow("NonFree", 50, 51200)
+			mock.ExpectQuery(q_GetLatestFullBackupID(28)).WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery(q_GetLogSegmentStats).WillReturnRows(rows1)
+		case "GoodNoPolicy":
+			/*No queries expected*/
+		case "TraceFilesDbError":
+			mock.ExpectQuery(f_GetTraceFiles(7)).WillReturnError(fmt.Errorf("DbError"))
+		case "BackupIdDbError":
+			mock.ExpectQuery(q_GetLatestFullBackupID(28)).WillReturnError(fmt.Errorf("DbError"))
+		case "BackupFilesDbError":
+			mock.ExpectQuery(q_GetLatestFullBackupID(28)).WillReturnRows(mock.NewRows([]string{"BACKUP_ID"}).AddRow("123"))
+			mock.ExpectQuery(f_GetTruncateData("123")).WillReturnError(fmt.Errorf("DbError"))
+		case "AlertsDbError":
+			mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnError(fmt.Errorf("DbError"))
+		case "LogDbError":
+			mock.ExpectQuery(q_GetLogSegmentStats).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.PlanHousekeeping(tt.args.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.PlanHousekeeping() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.PlanHousekeeping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestHanaUtilClient_ApplyHousekeeping(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	logCols := []string{"HOST", "PORT", "SERVICE_NAME", "SEGMENTS", "BYTES"}
+	allPolicy := HousekeepingPolicy{true, 7, true, 28, false, true, 42, true}
+	allPlan := &HousekeepingPlan{allPolicy, []HousekeepingStep{
+		{StepTraceFiles, "", 2, 192000, false, []TraceFile{
+			{"hana01", "nameserver_hana01.30001.000.trc", 64000, genTime},
+			{"hana01", "indexserver_hana01.30003.000.trc", 128000, genTime}}, ""},
+		{StepBackupCatalog, "", 100, 0, false, nil, "123"},
+		{StepStatServerAlerts, "", 99, 0, false, nil, ""},
+		{StepReclaimLog, "", 10, 10240, false, nil, ""}}}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		plan *HousekeepingPlan
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *HousekeepingReport
+		wantErr bool
+	}{
+		{"GoodAll", fields{db1, ""}, args{allPlan}, &HousekeepingReport{[]HousekeepingStepResult{
+			{StepTraceFiles, 1, 128000, 1, false},
+			{StepBackupCatalog, 100, 0, 0, false},
+			{StepStatServerAlerts, 99, 0, 0, false},
+			{StepReclaimLog, 10, 10240, 0, false}}}, false},
+		{"GoodSkipped", fields{db1, ""}, args{&HousekeepingPlan{allPolicy, []HousekeepingStep{
+			{StepReclaimLog, "", 0, 0, true, nil, ""}}}}, &HousekeepingReport{[]HousekeepingStepResult{
+			{StepReclaimLog, 0, 0, 0, true}}}, false},
+		{"AlertsDbError", fields{db1, ""}, args{&HousekeepingPlan{allPolicy, []HousekeepingStep{
+			{StepReclaimLog, "", 0, 0, true, nil, ""},
+			{StepStatServerAlerts, "", 99, 0, false, nil, ""}}}}, &HousekeepingReport{[]HousekeepingStepResult{
+			{StepReclaimLog, 0, 0, 0, true}}}, true},
+		{"UnexpectedStep", fields{db1, ""}, args{&HousekeepingPlan{allPolicy, []HousekeepingStep{
+			{"unknown", "", 0, 0, false, nil, ""}}}}, &HousekeepingReport{[]HousekeepingStepResult{}}, true},
+		{"BackupWithoutID", fields{db1, ""}, args{&HousekeepingPlan{allPolicy, []HousekeepingStep{
+			{StepBackupCatalog, "", 100, 0, false, nil, ""}}}}, &HousekeepingReport{[]HousekeepingStepResult{
+			{StepBackupCatalog, 100, 0, 0, false}}}, false},
+		{"InvalidBackupID", fields{db1, ""}, args{&HousekeepingPlan{allPolicy, []HousekeepingStep{
+			{StepBackupCatalog, "", 100, 0, false, nil, "123 OR 1=1"}}}}, &HousekeepingReport{[]HousekeepingStepResult{}}, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "GoodAll":
+			/*The first trace file is open and can't be removed*/
+			mock.ExpectQuery(q_GetTraceFile).WithArgs("hana01", "nameserver_hana01.30001.000.trc").WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(1))
+			mock.ExpectExec(f_RemoveTraceFile("hana01", "nameserver_hana01.30001.000.trc")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetTraceFile).WithArgs("hana01", "nameserver_hana01.30001.000.trc").WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(1))
+			mock.ExpectQuery(q_GetTraceFile).WithArgs("hana01", "indexserver_hana01.30003.000.trc").WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(1))
+			mock.ExpectExec(f_RemoveTraceFile("hana01", "indexserver_hana01.30003.000.trc")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetTraceFile).WithArgs("hana01", "indexserver_hana01.30003.000.trc").WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(0))
+			/*The backup found by the plan is truncated, not the latest*/
+			mock.ExpectQuery(f_GetTruncateData("123")).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow(100, 1024000))
+			mock.ExpectExec(f_GetBackupDelete("123")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(f_GetTruncateData("123")).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow(0, 0))
+			mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(99))
+			mock.ExpectExec(f_RemoveStatServerAlerts(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(0))
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(mock.NewRows(logCols).AddRow("hana01", 30003, "indexserver", 10, 10240))
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(mock.NewRows([]string{"CURRENT_TIME"}).AddRow(genTime))
+			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(mock.NewRows(logCols).AddRow("hana01", 30003, "indexserver", 0, 0))
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(mock.NewRows([]string{"CURRENT_TIME"}).AddRow(genTime))
+		case "GoodSkipped":
+			/*No queries expected*/
+		case "AlertsDbError":
+			mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnError(fmt.Errorf("DbError"))
+		case "UnexpectedStep":
+			/*No queries expected*/
+		case "BackupWithoutID":
+			/*A plan without a backup ID truncates relative to the latest full backup*/
+			mock.ExpectQuery(q_GetLatestFullBackupID(28)).WillReturnRows(mock.NewRows([]string{"BACKUP_ID"}).AddRow("456"))
+			mock.ExpectQuery(f_GetTruncateData("456")).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow(100, 1024000))
+			mock.ExpectExec(f_GetBackupDelete("456")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(f_GetTruncateData("456")).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow(0, 0))
+		case "InvalidBackupID":
+			/*No queries expected*/
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.ApplyHousekeeping(tt.args.plan)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.ApplyHousekeeping() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.ApplyHousekeeping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestHousekeepingReport_Totals(t *testing.T) {
+	hr := &HousekeepingReport{[]HousekeepingStepResult{
+		{StepTraceFiles, 2, 1024, 0, false},
+		{StepStatServerAlerts, 99, 0, 0, false},
+		{StepReclaimLog, 10, 10240, 0, false}}}
+	if got := hr.EntriesRemoved(); got != 111 {
+		t.Errorf("HousekeepingReport.EntriesRemoved() = %v, want %v", got, 111)
+	}
+	if got := hr.BytesRemoved(); got != 11264 {
+		t.Errorf("HousekeepingReport.BytesRemoved() = %v, want %v", got, 11264)
+	}
+}
@@ -0,0 +1,62 @@
+package hanautil
+
+import (
+	"context"
+	"time"
+)
+
+/******************************************************************************/
+/* This file defines the Client interface, which describes every operation   */
+/* of HanaUtilClient so that consumers can substitute a fake in their tests.  */
+/******************************************************************************/
+
+// Client describes the operations provided by HanaUtilClient. Code that uses
+// hanautil can accept a Client rather than a *HanaUtilClient so that it may be
+// unit tested against a fake implementation. See the documentation of
+// HanaUtilClient for the behaviour of each operation.
+type Client interface {
+	Connect() error
+	Close() error
+
+	GetVersion() (string, error)
+	GetTraceFiles(days uint) ([]TraceFile, error)
+	GetBackupSummary() (*BackupSummary, error)
+	GetFullBackupId(days int) (string, error)
+	GetBackupSummaryBeforeBackupID(b string) (*BackupSummary, error)
+	GetBackupFilesBeforeBackupID(b string) (*BackupFileStats, error)
+	GetBackupSummaryFiltered(filter BackupFilter) (*BackupSummary, error)
+	GetLatestBackups() (*LatestBackups, error)
+	GetBackupCatalogTrend(days uint, thresholdBytes uint64) (*BackupCatalogTrend, error)
+	GetStatServerAlerts(days uint) (uint, error)
+	GetLogSegmentStats() (*LogSegmentsStats, error)
+	GetDiskUsage() ([]DiskUsage, error)
+	GetVolumeUsage() ([]VolumeUsage, error)
+	GetMemoryUsage(topN uint) (*MemoryUsage, error)
+	GetTableSizes(filter TableSizeFilter) ([]TableSize, error)
+	GetTableUnloads(days uint) ([]TableUnload, error)
+	GetDeltaMergeCandidates(thresholds DeltaMergeThresholds) ([]DeltaMergeCandidate, error)
+	ListStatServerAlerts(filter StatServerAlertFilter) ([]StatServerAlert, error)
+	SummariseStatServerAlerts(filter StatServerAlertFilter) ([]StatServerAlertSummary, error)
+	GetStatisticsHistorySizes() ([]StatisticsHistoryTable, error)
+	GetAuditLogStats() (*AuditLogStats, error)
+	GetSqlPlanCacheStats() (*SqlPlanCacheStats, error)
+	GetExpensiveStatementsStats() (*ExpensiveStatementsStats, error)
+
+	RemoveTraceFile(host, filename string) error
+	TruncateBackupCatalog(days int, complete bool) (*TruncateStats, error)
+	TruncateBackupCatalogBeforeBackupID(b string, complete bool) (*TruncateStats, error)
+	RemoveStatServerAlerts(days uint) (uint64, error)
+	RemoveStatServerAlertsBatched(ctx context.Context, days uint, opts BatchOptions) (*BatchDeleteStats, error)
+	PurgeStatisticsHistory(days uint) ([]StatisticsHistoryPurge, error)
+	TruncateAuditLog(before time.Time) (uint64, error)
+	ClearSqlPlanCache() (*ClearStats, error)
+	ClearExpensiveStatementsTrace() (*ClearStats, error)
+	ReclaimLog() (*ReclaimResult, error)
+	MergeDelta(schema, table string) (*MergeStats, error)
+
+	PlanHousekeeping(policy HousekeepingPolicy) (*HousekeepingPlan, error)
+	ApplyHousekeeping(plan *HousekeepingPlan) (*HousekeepingReport, error)
+}
+
+// HanaUtilClient must implement Client
+var _ Client = (*HanaUtilClient)(nil)
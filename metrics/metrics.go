@@ -0,0 +1,224 @@
+// Package metrics provides a Prometheus collector that reports the backup
+// catalog, log segments, trace files and statistics server alerts of a HANA
+// database using a hanautil client, along with an HTTP handler that serves the
+// metrics on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hana"
+
+// Source is the subset of hanautil.HanaUtilClient used by the Collector
+type Source interface {
+	GetBackupSummary() (*hanautil.BackupSummary, error)
+	GetLatestBackups() (*hanautil.LatestBackups, error)
+	GetLogSegmentStats() (*hanautil.LogSegmentsStats, error)
+	GetTraceFiles(days uint) ([]hanautil.TraceFile, error)
+	GetStatServerAlerts(days uint) (uint, error)
+}
+
+// Options configures a Collector. Trace files and statistics server alerts are
+// only counted when they are older than TraceRetentionDays and
+// AlertRetentionDays respectively. If CacheDuration is not 0, the metrics of a
+// scrape are reused by further scrapes for that duration so that frequent
+// scrapes do not load the database. A scrape in which any area failed is not
+// reused, so the next scrape tries again.
+type Options struct {
+	TraceRetentionDays uint
+	AlertRetentionDays uint
+	CacheDuration      time.Duration
+}
+
+// Collector is a prometheus.Collector backed by a Source. Scrapes are
+// serialised so that only one set of queries runs against the database at a
+// time. If the queries for one area of the database fail, the metrics of that
+// area are omitted and hana_scrape_collector_success is 0 for the area.
+type Collector struct {
+	src  Source
+	opts Options
+	now  func() time.Time
+
+	mu       sync.Mutex
+	cached   []prometheus.Metric
+	cachedAt time.Time
+
+	scrapeSuccess        *prometheus.Desc
+	backupCatalogEntries *prometheus.Desc
+	backups              *prometheus.Desc
+	backupBytes          *prometheus.Desc
+	otherBackups         *prometheus.Desc
+	otherBackupBytes     *prometheus.Desc
+	backupCatalogBytes   *prometheus.Desc
+	latestBackupAge      *prometheus.Desc
+	logSegments          *prometheus.Desc
+	logSegmentBytes      *prometheus.Desc
+	traceFiles           *prometheus.Desc
+	traceFileBytes       *prometheus.Desc
+	statServerAlerts     *prometheus.Desc
+}
+
+// NewCollector returns a Collector that reads metrics from 'src', which is
+// usually a connected *hanautil.HanaUtilClient.
+func NewCollector(src Source, opts Options) *Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+	}
+	return &Collector{
+		src:  src,
+		opts: opts,
+		now:  time.Now,
+
+		scrapeSuccess:        desc("scrape_collector_success", "Whether the metrics of an area of the database were read successfully.", "collector"),
+		backupCatalogEntries: desc("backup_catalog_entries", "Number of entries in the backup catalog."),
+		backups:              desc("backups", "Number of backups in the backup catalog by type.", "type"),
+		backupBytes:          desc("backup_size_bytes", "Size in bytes of the backups in the backup catalog by type.", "type"),
+		otherBackups:         desc("other_backups", "Number of entries in the backup catalog of an entry type not reported by hana_backups.", "entry_type"),
+		otherBackupBytes:     desc("other_backup_size_bytes", "Size in bytes of the entries in the backup catalog of an entry type not reported by hana_backup_size_bytes.", "entry_type"),
+		backupCatalogBytes:   desc("backup_catalog_size_bytes", "Size in bytes of the latest backup of the backup catalog."),
+		latestBackupAge:      desc("latest_backup_age_seconds", "Seconds since the start of the newest successful backup by type.", "type"),
+		logSegments:          desc("log_segments", "Number of log segments by state.", "state"),
+		logSegmentBytes:      desc("log_segment_size_bytes", "Size in bytes of log segments by state.", "state"),
+		traceFiles:           desc("old_trace_files", "Number of trace files older than the trace retention."),
+		traceFileBytes:       desc("old_trace_file_size_bytes", "Size in bytes of trace files older than the trace retention."),
+		statServerAlerts:     desc("old_stat_server_alerts", "Number of statistics server alerts older than the alert retention."),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.scrapeSuccess, c.backupCatalogEntries, c.backups, c.backupBytes,
+		c.otherBackups, c.otherBackupBytes, c.backupCatalogBytes, c.latestBackupAge, c.logSegments, c.logSegmentBytes,
+		c.traceFiles, c.traceFileBytes, c.statServerAlerts,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	ms := c.cached
+	if ms == nil || c.opts.CacheDuration == 0 || now.Sub(c.cachedAt) >= c.opts.CacheDuration {
+		var ok bool
+		ms, ok = c.scrape()
+		/*Failed scrapes are not cached so that they are retried*/
+		c.cached = nil
+		if ok {
+			c.cached, c.cachedAt = ms, now
+		}
+	}
+	for _, m := range ms {
+		ch <- m
+	}
+}
+
+// scrape reads every area of the database and returns the resulting metrics
+// and whether every area was read successfully
+func (c *Collector) scrape() ([]prometheus.Metric, bool) {
+	ms := make([]prometheus.Metric, 0)
+	ok := true
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ms = append(ms, prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...))
+	}
+	success := func(collector string, err error) {
+		v := 1.0
+		if err != nil {
+			v = 0
+			ok = false
+		}
+		gauge(c.scrapeSuccess, v, collector)
+	}
+
+	bs, err := c.src.GetBackupSummary()
+	success("backup_catalog", err)
+	if err == nil {
+		gauge(c.backupCatalogEntries, float64(bs.BackupCatalogEntries))
+		for _, b := range []struct {
+			label string
+			count uint64
+			bytes uint64
+		}{
+			{"full", bs.FullBackups, bs.SizeOfFullBackupsBytes},
+			{"log", bs.LogBackups, bs.SizeOfLogBackupBytes},
+			{"incremental", bs.IncrementalBackups, bs.SizeOfIncrementalBackups},
+			{"differential", bs.DifferentialBackups, bs.SizeOfDifferentialBackups},
+			{"log_missing", bs.LogMissing, bs.SizeOfLogMissing},
+			{"data_snapshot", bs.DataSnapshots, bs.SizeOfDataSnapshots},
+		} {
+			gauge(c.backups, float64(b.count), b.label)
+			gauge(c.backupBytes, float64(b.bytes), b.label)
+		}
+		for _, name := range bs.OtherTypes() {
+			gauge(c.otherBackups, float64(bs.Types[name].Count), name)
+			gauge(c.otherBackupBytes, float64(bs.Types[name].SizeBytes), name)
+		}
+		gauge(c.backupCatalogBytes, float64(bs.SizeOfBackupCatalog))
+	}
+
+	lb, err := c.src.GetLatestBackups()
+	success("latest_backups", err)
+	if err == nil {
+		/*No age is reported for a type that has never been backed up*/
+		if !lb.FullBackup.IsZero() {
+			gauge(c.latestBackupAge, lb.CurrentDbTime.Sub(lb.FullBackup).Seconds(), "full")
+		}
+		if !lb.LogBackup.IsZero() {
+			gauge(c.latestBackupAge, lb.CurrentDbTime.Sub(lb.LogBackup).Seconds(), "log")
+		}
+	}
+
+	ls, err := c.src.GetLogSegmentStats()
+	success("log_segments", err)
+	if err == nil {
+		gauge(c.logSegments, float64(ls.FreeSegments), "free")
+		gauge(c.logSegmentBytes, float64(ls.TotalFreeSegmentBytes), "free")
+		gauge(c.logSegments, float64(ls.NonFreeSegments), "non_free")
+		gauge(c.logSegmentBytes, float64(ls.TotalNonFreeSegmentBytes), "non_free")
+	}
+
+	tf, err := c.src.GetTraceFiles(c.opts.TraceRetentionDays)
+	success("trace_files", err)
+	if err == nil {
+		var bytes uint64
+		for _, t := range tf {
+			bytes += t.FileSizeBytes
+		}
+		gauge(c.traceFiles, float64(len(tf)))
+		gauge(c.traceFileBytes, float64(bytes))
+	}
+
+	alerts, err := c.src.GetStatServerAlerts(c.opts.AlertRetentionDays)
+	success("stat_server_alerts", err)
+	if err == nil {
+		gauge(c.statServerAlerts, float64(alerts))
+	}
+
+	return ms, ok
+}
+
+// NewHandler returns an http.Handler that serves the metrics of 'c' on
+// /metrics. The metrics are served from their own registry, so the metrics of
+// the Go runtime are not included.
+func NewHandler(c prometheus.Collector) (http.Handler, error) {
+	reg := prometheus.NewRegistry()
+	err := reg.Register(c)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	return mux, nil
+}
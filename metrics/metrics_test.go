@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeSource returns fixed results and counts the number of scrapes
+type fakeSource struct {
+	scrapes  int
+	failLogs bool
+}
+
+var genTime = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func (f *fakeSource) GetBackupSummary() (*hanautil.BackupSummary, error) {
+	f.scrapes++
+	return &hanautil.BackupSummary{
+		BackupCatalogEntries:   120,
+		FullBackups:            10,
+		LogBackups:             100,
+		IncrementalBackups:     5,
+		DifferentialBackups:    3,
+		LogMissing:             1,
+		DataSnapshots:          1,
+		SizeOfFullBackupsBytes: 10240,
+		SizeOfLogBackupBytes:   2048,
+		SizeOfBackupCatalog:    512,
+		Types: map[string]hanautil.BackupTypeStats{
+			hanautil.EntryTypeFullBackup: {Count: 10, SizeBytes: 10240},
+			hanautil.EntryTypeLogBackup:  {Count: 100, SizeBytes: 2048},
+			"tape backup":                {Count: 2, SizeBytes: 4096},
+		},
+	}, nil
+}
+
+func (f *fakeSource) GetLatestBackups() (*hanautil.LatestBackups, error) {
+	return &hanautil.LatestBackups{FullBackup: genTime.Add(-time.Hour), CurrentDbTime: genTime}, nil
+}
+
+func (f *fakeSource) GetLogSegmentStats() (*hanautil.LogSegmentsStats, error) {
+	if f.failLogs {
+		return nil, fmt.Errorf("DbError")
+	}
+	return &hanautil.LogSegmentsStats{FreeSegments: 4, TotalFreeSegmentBytes: 4096, NonFreeSegments: 2, TotalNonFreeSegmentBytes: 2048}, nil
+}
+
+func (f *fakeSource) GetTraceFiles(days uint) ([]hanautil.TraceFile, error) {
+	return []hanautil.TraceFile{{FileSizeBytes: 100}, {FileSizeBytes: 200}}, nil
+}
+
+func (f *fakeSource) GetStatServerAlerts(days uint) (uint, error) {
+	return 42, nil
+}
+
+const wantMetrics = `
+# HELP hana_backup_catalog_entries Number of entries in the backup catalog.
+# TYPE hana_backup_catalog_entries gauge
+hana_backup_catalog_entries 120
+# HELP hana_backups Number of backups in the backup catalog by type.
+# TYPE hana_backups gauge
+hana_backups{type="data_snapshot"} 1
+hana_backups{type="differential"} 3
+hana_backups{type="full"} 10
+hana_backups{type="incremental"} 5
+hana_backups{type="log"} 100
+hana_backups{type="log_missing"} 1
+# HELP hana_latest_backup_age_seconds Seconds since the start of the newest successful backup by type.
+# TYPE hana_latest_backup_age_seconds gauge
+hana_latest_backup_age_seconds{type="full"} 3600
+# HELP hana_other_backups Number of entries in the backup catalog of an entry type not reported by hana_backups.
+# TYPE hana_other_backups gauge
+hana_other_backups{entry_type="tape backup"} 2
+# HELP hana_other_backup_size_bytes Size in bytes of the entries in the backup catalog of an entry type not reported by hana_backup_size_bytes.
+# TYPE hana_other_backup_size_bytes gauge
+hana_other_backup_size_bytes{entry_type="tape backup"} 4096
+# HELP hana_log_segment_size_bytes Size in bytes of log segments by state.
+# TYPE hana_log_segment_size_bytes gauge
+hana_log_segment_size_bytes{state="free"} 4096
+hana_log_segment_size_bytes{state="non_free"} 2048
+# HELP hana_old_stat_server_alerts Number of statistics server alerts older than the alert retention.
+# TYPE hana_old_stat_server_alerts gauge
+hana_old_stat_server_alerts 42
+# HELP hana_old_trace_file_size_bytes Size in bytes of trace files older than the trace retention.
+# TYPE hana_old_trace_file_size_bytes gauge
+hana_old_trace_file_size_bytes 300
+`
+
+func TestCollector_Collect(t *testing.T) {
+	src := &fakeSource{}
+	c := NewCollector(src, Options{})
+	err := testutil.CollectAndCompare(c, strings.NewReader(wantMetrics),
+		"hana_backup_catalog_entries", "hana_backups", "hana_latest_backup_age_seconds",
+		"hana_other_backups", "hana_other_backup_size_bytes",
+		"hana_log_segment_size_bytes", "hana_old_stat_server_alerts", "hana_old_trace_file_size_bytes")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_CollectFailure(t *testing.T) {
+	src := &fakeSource{failLogs: true}
+	c := NewCollector(src, Options{})
+	want := `
+# HELP hana_scrape_collector_success Whether the metrics of an area of the database were read successfully.
+# TYPE hana_scrape_collector_success gauge
+hana_scrape_collector_success{collector="backup_catalog"} 1
+hana_scrape_collector_success{collector="latest_backups"} 1
+hana_scrape_collector_success{collector="log_segments"} 0
+hana_scrape_collector_success{collector="stat_server_alerts"} 1
+hana_scrape_collector_success{collector="trace_files"} 1
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(want), "hana_scrape_collector_success", "hana_log_segments")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_Cache(t *testing.T) {
+	tests := []struct {
+		name     string
+		cache    time.Duration
+		step     time.Duration
+		failLogs bool
+		want     int
+	}{
+		{"NoCache", 0, time.Second, false, 3},
+		{"Cached", time.Minute, time.Second, false, 1},
+		{"Expired", time.Minute, time.Minute, false, 3},
+		{"FailureNotCached", time.Minute, time.Second, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &fakeSource{failLogs: tt.failLogs}
+			c := NewCollector(src, Options{CacheDuration: tt.cache})
+			now := genTime
+			c.now = func() time.Time { return now }
+			for i := 0; i < 3; i++ {
+				testutil.CollectAndCount(c)
+				now = now.Add(tt.step)
+			}
+			if src.scrapes != tt.want {
+				t.Errorf("scrapes = %d, want %d", src.scrapes, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollector_CacheRecovery(t *testing.T) {
+	src := &fakeSource{failLogs: true}
+	c := NewCollector(src, Options{CacheDuration: time.Minute})
+	c.now = func() time.Time { return genTime }
+	testutil.CollectAndCount(c)
+
+	/*The area recovers before the cache would have expired*/
+	src.failLogs = false
+	want := `
+# HELP hana_log_segments Number of log segments by state.
+# TYPE hana_log_segments gauge
+hana_log_segments{state="free"} 4
+hana_log_segments{state="non_free"} 2
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(want), "hana_log_segments")
+	if err != nil {
+		t.Error(err)
+	}
+	testutil.CollectAndCount(c)
+	if src.scrapes != 2 {
+		t.Errorf("scrapes = %d, want 2", src.scrapes)
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	h, err := NewHandler(NewCollector(&fakeSource{}, Options{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "hana_backup_catalog_entries 120") {
+		t.Errorf("NewHandler() body does not hold hana_backup_catalog_entries, got %s", body)
+	}
+
+	res, err = srv.Client().Get(srv.URL + "/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("NewHandler() /other status = %d, want 404", res.StatusCode)
+	}
+}
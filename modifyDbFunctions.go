@@ -1,6 +1,14 @@
 package hanautil
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // RemoveTraceFile deletes HANA trace files. Use the the
 // GetTraceFiles function to find candidates for removal. The function takes two
@@ -17,7 +25,13 @@ import "fmt"
 // In the unlikely occurrence that host and file name combination does not yield
 // a unique result, the error 'TraceFileNotUnique' will be returned.
 func (h *HanaUtilClient) RemoveTraceFile(host, filename string) error {
-	r1 := h.db.QueryRow(f_GetTraceFile(host, filename))
+	ctx, span := h.startSpan(context.Background(), "RemoveTraceFile",
+		attribute.String("hana.host", host),
+		attribute.String("hana.file_name", filename),
+	)
+	defer span.End()
+
+	r1 := h.queryRow(ctx, "q_GetTraceFile", q_GetTraceFile, host, filename)
 	var count uint32
 	err := r1.Scan(&count)
 	if err != nil {
@@ -30,7 +44,7 @@ func (h *HanaUtilClient) RemoveTraceFile(host, filename string) error {
 		return fmt.Errorf("TraceFileNotUnique")
 	}
 
-	_, err = h.db.Exec(f_RemoveTraceFile(host, filename))
+	_, err = h.exec(ctx, "f_RemoveTraceFile", f_RemoveTraceFile(host, filename))
 	if err != nil {
 		// Promote DB error
 		return err
@@ -38,7 +52,7 @@ func (h *HanaUtilClient) RemoveTraceFile(host, filename string) error {
 
 	/*As we can't check if a trace file is actually open or not, check if it
 	still exists and if it does return the 'TraceFileNotRemoved' error*/
-	r3 := h.db.QueryRow(f_GetTraceFile(host, filename))
+	r3 := h.queryRow(ctx, "q_GetTraceFile", q_GetTraceFile, host, filename)
 	err = r3.Scan(&count)
 	if err != nil {
 		return err
@@ -69,33 +83,71 @@ func (h *HanaUtilClient) RemoveTraceFile(host, filename string) error {
 // and the error will be nil. However, if the function fails, the pointer to
 // `TruncateStats` will be nil and the error will be populated.
 func (h *HanaUtilClient) TruncateBackupCatalog(days int, complete bool) (*TruncateStats, error) {
-	tr := TruncateStats{}
+	ctx, span := h.startSpan(context.Background(), "TruncateBackupCatalog",
+		attribute.Int("hana.days", days),
+		attribute.Bool("hana.complete", complete),
+	)
+	defer span.End()
+
 	//First find the last full backup that is older than the given days
-	r1 := h.db.QueryRow(q_GetLatestFullBackupID(uint(days)))
+	r1 := h.queryRow(ctx, "q_GetLatestFullBackupID", q_GetLatestFullBackupID(uint(days)))
 	var backupId string
 	err := r1.Scan(&backupId)
 	if err != nil {
 		/*PromoteError*/
 		return nil, err
 	}
+	span.SetAttributes(attribute.String("hana.backup_id", backupId))
+
+	return h.truncateBeforeBackupID(ctx, span, backupId, complete)
+}
+
+// TruncateBackupCatalogBeforeBackupID removes entries from the HANA database
+// backup catalog before the backup given by 'b', as TruncateBackupCatalog does
+// once it has found the latest full backup older than its 'days'. This allows
+// a backup ID found earlier, for example by GetFullBackupId when a
+// housekeeping plan is built, to be truncated even if newer full backups have
+// been taken since. The `complete` argument is as for TruncateBackupCatalog.
+// Errors returned are 'InvalidBackupID', when 'b' is not a number, or a DB
+// driver error promoted directly from the DB.
+func (h *HanaUtilClient) TruncateBackupCatalogBeforeBackupID(b string, complete bool) (*TruncateStats, error) {
+	ctx, span := h.startSpan(context.Background(), "TruncateBackupCatalogBeforeBackupID",
+		attribute.String("hana.backup_id", b),
+		attribute.Bool("hana.complete", complete),
+	)
+	defer span.End()
+
+	/*The ID is placed in the query, so it must be a number*/
+	_, err := strconv.ParseUint(b, 10, 64)
+	if err != nil {
+		return nil, spanError(ctx, span, fmt.Errorf("InvalidBackupID"))
+	}
+
+	return h.truncateBeforeBackupID(ctx, span, b, complete)
+}
 
+// truncateBeforeBackupID removes the backup catalog entries before the backup
+// 'backupId' and reports what was removed on 'span', the span of the calling
+// operation
+func (h *HanaUtilClient) truncateBeforeBackupID(ctx context.Context, span trace.Span, backupId string, complete bool) (*TruncateStats, error) {
+	tr := TruncateStats{}
 	var truncFiles uint64
 	var truncBytes uint64
-	r2 := h.db.QueryRow(f_GetTruncateData(backupId))
-	err = r2.Scan(&truncFiles, &truncBytes)
+	r2 := h.queryRow(ctx, "f_GetTruncateData", f_GetTruncateData(backupId))
+	err := r2.Scan(&truncFiles, &truncBytes)
 	if err != nil {
 		/*PromoteError*/
 		return nil, err
 	}
 
 	if complete {
-		_, err = h.db.Exec(f_GetBackupDeleteComplete(backupId))
+		_, err = h.exec(ctx, "f_GetBackupDeleteComplete", f_GetBackupDeleteComplete(backupId))
 		if err != nil {
 			/*Promote error*/
 			return nil, err
 		}
 	} else {
-		_, err = h.db.Exec(f_GetBackupDelete(backupId))
+		_, err = h.exec(ctx, "f_GetBackupDelete", f_GetBackupDelete(backupId))
 		if err != nil {
 			/*Promote error*/
 			return nil, err
@@ -106,7 +158,7 @@ func (h *HanaUtilClient) TruncateBackupCatalog(days int, complete bool) (*Trunca
 	check */
 	var postTruncFiles uint64
 	var postTruncBytes uint64
-	r3 := h.db.QueryRow(f_GetTruncateData(backupId))
+	r3 := h.queryRow(ctx, "f_GetTruncateData", f_GetTruncateData(backupId))
 	err = r3.Scan(&postTruncFiles, &postTruncBytes)
 	if err != nil {
 		/*PromoteError*/
@@ -133,6 +185,10 @@ func (h *HanaUtilClient) TruncateBackupCatalog(days int, complete bool) (*Trunca
 		tr.BytesRemoved = 0
 	}
 
+	span.SetAttributes(
+		attribute.Int64("hana.files_removed", int64(tr.FilesRemoved)),
+		attribute.Int64("hana.bytes_removed", int64(tr.BytesRemoved)),
+	)
 	return &tr, nil
 }
 
@@ -142,8 +198,13 @@ func (h *HanaUtilClient) TruncateBackupCatalog(days int, complete bool) (*Trunca
 // The function returns a uint64 and an error. If the function is successful,
 // the uint64 represents which represents the number of alerts removed from
 func (h *HanaUtilClient) RemoveStatServerAlerts(days uint) (uint64, error) {
+	ctx, span := h.startSpan(context.Background(), "RemoveStatServerAlerts",
+		attribute.Int64("hana.days", int64(days)),
+	)
+	defer span.End()
+
 	var preRemove uint64
-	r1 := h.db.QueryRow(f_GetStatServerAlerts(days))
+	r1 := h.queryRow(ctx, "f_GetStatServerAlerts", f_GetStatServerAlerts(days))
 	err := r1.Scan(&preRemove)
 	if err != nil {
 		/*PromoteError*/
@@ -151,13 +212,13 @@ func (h *HanaUtilClient) RemoveStatServerAlerts(days uint) (uint64, error) {
 	}
 
 	/*Now do the deletion*/
-	_, err = h.db.Exec(f_RemoveStatServerAlerts(days))
+	_, err = h.exec(ctx, "f_RemoveStatServerAlerts", f_RemoveStatServerAlerts(days))
 	if err != nil {
 		/*PromoteError*/
 		return 0, err
 	}
 	var postRemove uint64
-	r2 := h.db.QueryRow(f_GetStatServerAlerts(days))
+	r2 := h.queryRow(ctx, "f_GetStatServerAlerts", f_GetStatServerAlerts(days))
 	err = r2.Scan(&postRemove)
 	if err != nil {
 		/*PromoteError*/
@@ -175,44 +236,424 @@ func (h *HanaUtilClient) RemoveStatServerAlerts(days uint) (uint64, error) {
 	}
 }
 
-// ReclaimLog removes all log segments in the log volume that are marked as
-// 'Free'. Freeing log segments lowers the amount of used space on the log
-// volume which is especially import in MDC environments. The function will
-// return the number of bytes removed from the log volumes and an error. If an
-// error occurs the returned uint64 will be zero and the error will be populated
-func (h *HanaUtilClient) ReclaimLog() (uint64, error) {
-	/*Get the amount of bytes consumed by free log segments before truncation*/
-	var preBytes uint64
-	row1 := h.db.QueryRow(q_GetFreeLogBytes)
-	err := row1.Scan(&preBytes)
+// RemoveStatServerAlertsBatched removes entries from the
+// SYS_STATISTICS.STATISTICS_ALERTS_BASE table that are older than the number of
+// days given in the 'days' argument. Unlike RemoveStatServerAlerts, rows are
+// removed in batches with a commit after each batch, which prevents long held
+// locks and large log volumes on systems with millions of historic alerts.
+//
+// The size of each batch and an optional progress callback are set with the
+// 'opts' argument. Deletion stops when a batch removes fewer rows than the batch
+// size or when 'ctx' is cancelled. Cancellation is checked between batches, a
+// batch in progress is rolled back.
+//
+// The function returns a pointer to the type `BatchDeleteStats` and an error.
+// As each batch is committed, `BatchDeleteStats` is always populated with the
+// batches that were committed, even if an error is returned. When cancelled,
+// the error returned is that of 'ctx'.
+func (h *HanaUtilClient) RemoveStatServerAlertsBatched(ctx context.Context, days uint, opts BatchOptions) (*BatchDeleteStats, error) {
+	ctx, span := h.startSpan(ctx, "RemoveStatServerAlertsBatched",
+		attribute.Int64("hana.days", int64(days)),
+	)
+	defer span.End()
+
+	bs := BatchDeleteStats{RowsPerBatch: make([]uint64, 0)}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	for {
+		err := ctx.Err()
+		if err != nil {
+			return &bs, err
+		}
+
+		removed, err := h.execBatch(ctx, "f_RemoveStatServerAlertsBatch", f_RemoveStatServerAlertsBatch(days, batchSize))
+		if err != nil {
+			/*PromoteError*/
+			return &bs, err
+		}
+
+		bs.RowsPerBatch = append(bs.RowsPerBatch, removed)
+		bs.TotalRemoved += removed
+		if opts.Progress != nil {
+			opts.Progress(BatchProgress{
+				Batch:        uint(len(bs.RowsPerBatch)),
+				RowsRemoved:  removed,
+				TotalRemoved: bs.TotalRemoved,
+			})
+		}
+
+		/*A short batch means there is nothing left to remove*/
+		if removed < uint64(batchSize) {
+			return &bs, nil
+		}
+	}
+}
+
+// execBatch executes a single statement named 'name' in its own transaction and
+// returns the number of rows affected once committed
+func (h *HanaUtilClient) execBatch(ctx context.Context, name, query string) (uint64, error) {
+	qctx, span := h.startQuerySpan(ctx, name, query)
+	defer span.End()
+
+	tx, err := h.db.BeginTx(qctx, nil)
+	if err != nil {
+		/*PromoteError*/
+		return 0, spanError(ctx, span, err)
+	}
+
+	res, err := tx.ExecContext(qctx, query)
+	if err != nil {
+		/*PromoteError, the original error is more useful than that of the
+		rollback*/
+		_ = tx.Rollback()
+		return 0, spanError(ctx, span, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, spanError(ctx, span, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		/*PromoteError*/
+		return 0, spanError(ctx, span, err)
+	}
+
+	if affected < 0 {
+		return 0, nil
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", affected))
+	return uint64(affected), nil
+}
+
+// PurgeStatisticsHistory removes rows older than the number of days given in
+// the 'days' argument from each of the statistics server collector history
+// tables (HOST_* and GLOBAL_*) in the _SYS_STATISTICS schema. Use
+// GetStatisticsHistorySizes to find how much history is held. As with
+// RemoveStatServerAlertsBatched, rows are removed in batches of
+// DefaultBatchSize with a commit after each batch.
+//
+// The function returns a slice of the type `StatisticsHistoryPurge` and an
+// error. If the function is successful, the slice holds the number of rows and
+// size of each table before and after the purge. As each batch is committed,
+// if a batch fails the slice holds the tables purged before the failure and
+// the table that failed along with the error. Their rows after are those
+// before less the rows removed by committed batches, their sizes are not read
+// again. If the sizes cannot be read before the purge, the slice will be nil.
+func (h *HanaUtilClient) PurgeStatisticsHistory(days uint) ([]StatisticsHistoryPurge, error) {
+	ctx, span := h.startSpan(context.Background(), "PurgeStatisticsHistory",
+		attribute.Int64("hana.days", int64(days)),
+	)
+	defer span.End()
+
+	preTables, err := h.withParent(ctx).GetStatisticsHistorySizes()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	purged := make([]StatisticsHistoryPurge, 0, len(preTables))
+	for _, t := range preTables {
+		p := StatisticsHistoryPurge{
+			TableName:   t.TableName,
+			RowsBefore:  t.RecordCount,
+			RowsAfter:   t.RecordCount,
+			BytesBefore: t.TableSizeBytes,
+			BytesAfter:  t.TableSizeBytes,
+		}
+		for {
+			removed, err := h.execBatch(ctx, "f_PurgeStatisticsHistoryBatch", f_PurgeStatisticsHistoryBatch(t.TableName, days, DefaultBatchSize))
+			/*Mitigation around potentially going less than zero on uint vars*/
+			if removed < p.RowsAfter {
+				p.RowsAfter -= removed
+			} else {
+				p.RowsAfter = 0
+			}
+			if err != nil {
+				/*PromoteError*/
+				return append(purged, p), err
+			}
+			/*A short batch means there is nothing left to remove*/
+			if removed < uint64(DefaultBatchSize) {
+				break
+			}
+		}
+		purged = append(purged, p)
+	}
+
+	postTables, err := h.withParent(ctx).GetStatisticsHistorySizes()
+	if err != nil {
+		/*PromoteError*/
+		return purged, err
+	}
+
+	/*Tables are matched on name, the order of the second query may differ as
+	the tables are ordered by size*/
+	after := make(map[string]StatisticsHistoryTable)
+	for _, t := range postTables {
+		after[t.TableName] = t
+	}
+	for i, p := range purged {
+		purged[i].RowsAfter = after[p.TableName].RecordCount
+		purged[i].BytesAfter = after[p.TableName].TableSizeBytes
+	}
+
+	return purged, nil
+}
+
+// TruncateAuditLog removes entries from the audit log table that are older
+// than the time given in the 'before' argument using ALTER SYSTEM CLEAR AUDIT
+// LOG. This only affects audit trails written to the CSTABLE target.
+// The function returns a uint64 and an error. If the function is successful,
+// the uint64 represents the number of audit log entries removed.
+func (h *HanaUtilClient) TruncateAuditLog(before time.Time) (uint64, error) {
+	ctx, span := h.startSpan(context.Background(), "TruncateAuditLog")
+	defer span.End()
+
+	var preRemove uint64
+	r1 := h.queryRow(ctx, "f_GetAuditLogEntriesBefore", f_GetAuditLogEntriesBefore(before))
+	err := r1.Scan(&preRemove)
 	if err != nil {
 		/*PromoteError*/
 		return 0, err
 	}
 
-	/*Execute the command*/
-	_, err = h.db.Exec(q_ReclaimLog)
+	/*Now do the deletion*/
+	_, err = h.exec(ctx, "f_ClearAuditLog", f_ClearAuditLog(before))
 	if err != nil {
 		/*PromoteError*/
 		return 0, err
 	}
 
-	/*Get the amount of bytes consumed by free log segments post truncation*/
-	var postBytes uint64
-	row2 := h.db.QueryRow(q_GetFreeLogBytes)
-	err = row2.Scan(&postBytes)
+	var postRemove uint64
+	r2 := h.queryRow(ctx, "f_GetAuditLogEntriesBefore", f_GetAuditLogEntriesBefore(before))
+	err = r2.Scan(&postRemove)
 	if err != nil {
 		/*PromoteError*/
 		return 0, err
 	}
 
-	/*There is a small chance that more segments become free and that the amount
-	of free segments following the truncation is actually larger than in the
-	beginning of the operation. In this case a 0 is returned informing the user
-	that there was a reduction of 0 bytes*/
-	if postBytes > preBytes {
-		return 0, nil
+	/*Mitigation around potentially going less than zero on uint vars*/
+	if postRemove <= preRemove {
+		return preRemove - postRemove, nil
 	} else {
-		return preBytes - postBytes, nil
+		return 0, nil
+	}
+}
+
+// ClearSqlPlanCache removes all plans from the SQL plan cache that are not
+// currently in use. Plans will be recompiled when next executed, so the cache
+// should only be cleared when it is using an excessive amount of memory.
+//
+// The function returns a pointer to the type `ClearStats` and an error. If the
+// function is successful, `ClearStats` holds the number of plans and bytes
+// removed from the cache. If the function fails, the pointer will be nil and the
+// error will be populated.
+func (h *HanaUtilClient) ClearSqlPlanCache() (*ClearStats, error) {
+	ctx, span := h.startSpan(context.Background(), "ClearSqlPlanCache")
+	defer span.End()
+
+	cs := ClearStats{}
+	pre, err := h.withParent(ctx).GetSqlPlanCacheStats()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	_, err = h.exec(ctx, "q_ClearSqlPlanCache", q_ClearSqlPlanCache)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	post, err := h.withParent(ctx).GetSqlPlanCacheStats()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Plans may be added to the cache as soon as it is cleared*/
+	/*Mitigation around potentially going less than zero on uint vars*/
+	if post.CachedPlans < pre.CachedPlans {
+		cs.EntriesRemoved = pre.CachedPlans - post.CachedPlans
+	}
+	if post.CachedPlanBytes < pre.CachedPlanBytes {
+		cs.BytesRemoved = pre.CachedPlanBytes - post.CachedPlanBytes
+	}
+
+	return &cs, nil
+}
+
+// ClearExpensiveStatementsTrace removes all entries from the in-memory
+// expensive statements trace.
+//
+// The function returns a pointer to the type `ClearStats` and an error. If the
+// function is successful, `ClearStats` holds the number of entries and bytes
+// removed from the trace. If the function fails, the pointer will be nil and the
+// error will be populated.
+func (h *HanaUtilClient) ClearExpensiveStatementsTrace() (*ClearStats, error) {
+	ctx, span := h.startSpan(context.Background(), "ClearExpensiveStatementsTrace")
+	defer span.End()
+
+	cs := ClearStats{}
+	pre, err := h.withParent(ctx).GetExpensiveStatementsStats()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	_, err = h.exec(ctx, "q_ClearExpensiveStatementsTrace", q_ClearExpensiveStatementsTrace)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	post, err := h.withParent(ctx).GetExpensiveStatementsStats()
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Mitigation around potentially going less than zero on uint vars*/
+	if post.Entries < pre.Entries {
+		cs.EntriesRemoved = pre.Entries - post.Entries
+	}
+	if post.MemoryBytes < pre.MemoryBytes {
+		cs.BytesRemoved = pre.MemoryBytes - post.MemoryBytes
+	}
+
+	return &cs, nil
+}
+
+// ReclaimLog removes all log segments in the log volume that are marked as
+// 'Free'. Freeing log segments lowers the amount of used space on the log
+// volume which is especially import in MDC environments.
+//
+// The function returns a pointer to the type `ReclaimResult` and an error. The
+// `ReclaimResult` holds the number and size of the free log segments for each
+// host and service before and after the reclaim, along with the database time
+// of each measurement. The total reclaimed can be found with the
+// `BytesReclaimed` method. If an error occurs the returned pointer will be nil
+// and the error will be populated.
+func (h *HanaUtilClient) ReclaimLog() (*ReclaimResult, error) {
+	ctx, span := h.startSpan(context.Background(), "ReclaimLog")
+	defer span.End()
+
+	rr := ReclaimResult{Services: make([]ServiceLogReclaim, 0)}
+	/*Get the free log segments of each service before truncation*/
+	preSegs, preTime, err := h.fetchFreeLogSegments(ctx)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Execute the command*/
+	_, err = h.exec(ctx, "q_ReclaimLog", q_ReclaimLog)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Get the free log segments of each service post truncation*/
+	postSegs, postTime, err := h.fetchFreeLogSegments(ctx)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Services are matched on host and port. A service may only appear in one
+	of the measurements if it was started or stopped during the reclaim, in
+	which case the missing measurement is reported as zero*/
+	idx := make(map[string]int)
+	for _, s := range preSegs {
+		idx[fmt.Sprintf("%s:%d", s.Hostname, s.Port)] = len(rr.Services)
+		rr.Services = append(rr.Services, ServiceLogReclaim{
+			Hostname:               s.Hostname,
+			Port:                   s.Port,
+			ServiceName:            s.ServiceName,
+			FreeSegmentsBefore:     s.FreeSegments,
+			FreeSegmentBytesBefore: s.FreeSegmentBytes,
+		})
+	}
+	for _, s := range postSegs {
+		i, ok := idx[fmt.Sprintf("%s:%d", s.Hostname, s.Port)]
+		if !ok {
+			i = len(rr.Services)
+			rr.Services = append(rr.Services, ServiceLogReclaim{
+				Hostname:    s.Hostname,
+				Port:        s.Port,
+				ServiceName: s.ServiceName,
+			})
+		}
+		rr.Services[i].FreeSegmentsAfter = s.FreeSegments
+		rr.Services[i].FreeSegmentBytesAfter = s.FreeSegmentBytes
+	}
+	rr.BeforeTime = preTime
+	rr.AfterTime = postTime
+
+	return &rr, nil
+}
+
+// MergeDelta merges the delta storage of the column store table given by the
+// 'schema' and 'table' arguments into main storage. Use the
+// GetDeltaMergeCandidates function to find candidates for merging.
+//
+// hanautil will first check that the table exists in the column store. If it
+// does not, the error 'TableNotFound' will be returned. Any database errors
+// discovered will be promoted as the returned error of this function.
+//
+// The function returns a pointer to the type `MergeStats` and an error. If the
+// function is successful, `MergeStats` will hold the number of records and
+// bytes of delta storage merged. If the function fails, the pointer will be
+// nil and the error will be populated.
+func (h *HanaUtilClient) MergeDelta(schema, table string) (*MergeStats, error) {
+	ctx, span := h.startSpan(context.Background(), "MergeDelta",
+		attribute.String("hana.schema_name", schema),
+		attribute.String("hana.table_name", table),
+	)
+	defer span.End()
+
+	ms := MergeStats{}
+	var partitions, preBytes, preRecords uint64
+	r1 := h.queryRow(ctx, "f_GetTableDelta", f_GetTableDelta(schema, table))
+	err := r1.Scan(&partitions, &preBytes, &preRecords)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
 	}
+
+	if partitions < 1 {
+		return nil, fmt.Errorf("TableNotFound")
+	}
+
+	_, err = h.exec(ctx, "f_MergeDelta", f_MergeDelta(schema, table))
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Writes may continue during the merge, so check what remains in the
+	delta*/
+	var postBytes, postRecords uint64
+	r2 := h.queryRow(ctx, "f_GetTableDelta", f_GetTableDelta(schema, table))
+	err = r2.Scan(&partitions, &postBytes, &postRecords)
+	if err != nil {
+		/*PromoteError*/
+		return nil, err
+	}
+
+	/*Mitigation around potentially going less than zero on uint vars*/
+	if postRecords < preRecords {
+		ms.RecordsMerged = preRecords - postRecords
+	}
+	if postBytes < preBytes {
+		ms.BytesMerged = preBytes - postBytes
+	}
+
+	return &ms, nil
 }
@@ -1,10 +1,12 @@
 package hanautil
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -192,6 +194,72 @@ func Test_hanaUtilClient_TruncateBackupCatalog(t *testing.T) {
 	}
 }
 
+func TestHanaUtilClient_TruncateBackupCatalogBeforeBackupID(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		b        string
+		complete bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *TruncateStats
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{"1038347234", false}, &TruncateStats{100, 0}, false},
+		{"GoodComplete", fields{db1, ""}, args{"1038347234", true}, &TruncateStats{99, 99999}, false},
+		{"InvalidBackupID", fields{db1, ""}, args{"1 OR 1=1", false}, nil, true},
+		{"TruncateDbError", fields{db1, ""}, args{"1038347234", false}, nil, true},
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		switch tt.name {
+		case "Good":
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow("100", "1024000"))
+			mock.ExpectExec(f_GetBackupDelete(tt.args.b)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow("0", "0"))
+		case "GoodComplete":
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow("100", "100000"))
+			mock.ExpectExec(f_GetBackupDeleteComplete(tt.args.b)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow("1", "1"))
+		case "InvalidBackupID":
+			/*No queries expected*/
+		case "TruncateDbError":
+			mock.ExpectQuery(f_GetTruncateData(tt.args.b)).WillReturnRows(mock.NewRows([]string{"FILES", "BACKUP_SIZE"}).AddRow("100", "1024000"))
+			mock.ExpectExec(f_GetBackupDelete(tt.args.b)).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.TruncateBackupCatalogBeforeBackupID(tt.args.b, tt.args.complete)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.TruncateBackupCatalogBeforeBackupID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.TruncateBackupCatalogBeforeBackupID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func Test_hanaUtilClient_RemoveTraceFile(t *testing.T) {
 	/*Test Setup*/
 	/*Mock DB*/
@@ -231,41 +299,41 @@ func Test_hanaUtilClient_RemoveTraceFile(t *testing.T) {
 		case "Good":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("1")
 			row2 := mock.NewRows([]string{"COUNT"}).AddRow("0")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 			mock.ExpectExec(f_RemoveTraceFile(tt.args.host, tt.args.filename)).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row2)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row2)
 		case "TraceNotRemoved":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("1")
 			row2 := mock.NewRows([]string{"COUNT"}).AddRow("1")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 			mock.ExpectExec(f_RemoveTraceFile(tt.args.host, tt.args.filename)).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row2)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row2)
 		case "2ndGetTraceDbError":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("1")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 			mock.ExpectExec(f_RemoveTraceFile(tt.args.host, tt.args.filename)).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnError(fmt.Errorf("DbError"))
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnError(fmt.Errorf("DbError"))
 		case "2ndGetTraceScanError":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("1")
 			row2 := mock.NewRows([]string{"COUNT"}).AddRow("1.5")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 			mock.ExpectExec(f_RemoveTraceFile(tt.args.host, tt.args.filename)).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row2)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row2)
 		case "RemoveTraceDbError":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("1")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 			mock.ExpectExec(f_RemoveTraceFile(tt.args.host, tt.args.filename)).WillReturnError(fmt.Errorf("DbError"))
 		case "TraceNotFound":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("0")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 		case "TraceNotUnique":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("2")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 		case "1stGetTraceDbError":
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnError(fmt.Errorf("DbError"))
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnError(fmt.Errorf("DbError"))
 		case "1stGetTraceScanError":
 			row1 := mock.NewRows([]string{"COUNT"}).AddRow("1.5")
-			mock.ExpectQuery(f_GetTraceFile(tt.args.host, tt.args.filename)).WillReturnRows(row1)
+			mock.ExpectQuery(q_GetTraceFile).WithArgs(tt.args.host, tt.args.filename).WillReturnRows(row1)
 		default:
 			fmt.Printf("No test case matched for %s\n", tt.name)
 			t.Errorf("No test case matched")
@@ -390,6 +458,12 @@ func Test_hanaUtilClient_ReclaimLog(t *testing.T) {
 		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
 	}
 	defer db1.Close()
+
+	//Generic timestamps
+	preTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	postTime := time.Date(2022, 1, 1, 12, 0, 5, 0, time.UTC)
+	cols := []string{"HOST", "PORT", "SERVICE_NAME", "SEGMENTS", "BYTES"}
+
 	type fields struct {
 		db  *sql.DB
 		dsn string
@@ -397,66 +471,118 @@ func Test_hanaUtilClient_ReclaimLog(t *testing.T) {
 	tests := []struct {
 		name    string
 		fields  fields
-		want    uint64
+		want    *ReclaimResult
 		wantErr bool
 	}{
-		{"GoodFullDelete", fields{db1, ""}, 1000, false},
-		{"GoodPartialDelete", fields{db1, ""}, 900, false},
-		{"GoodNoDelete", fields{db1, ""}, 0, false},
-		{"GoodNoDeleteMoreLog", fields{db1, ""}, 0, false},
-		{"2ndGetFreeLogBytesDbError", fields{db1, ""}, 0, true},
-		{"2ndGetFreeLogBytesScanError", fields{db1, ""}, 0, true},
-		{"ReclaimLogDbError", fields{db1, ""}, 0, true},
-		{"1stGetFreeLogBytesDbError", fields{db1, ""}, 0, true},
-		{"1stGetFreeLogBytesScanError", fields{db1, ""}, 0, true},
+		{"GoodFullDelete", fields{db1, ""}, &ReclaimResult{[]ServiceLogReclaim{
+			{"hana01", 30001, "nameserver", 2, 1000, 0, 0},
+			{"hana01", 30003, "indexserver", 10, 5000, 0, 0}}, preTime, postTime}, false},
+		{"GoodPartialDelete", fields{db1, ""}, &ReclaimResult{[]ServiceLogReclaim{
+			{"hana01", 30003, "indexserver", 10, 1000, 1, 100}}, preTime, postTime}, false},
+		{"GoodNoDelete", fields{db1, ""}, &ReclaimResult{[]ServiceLogReclaim{
+			{"hana01", 30003, "indexserver", 2, 1000, 2, 1000}}, preTime, postTime}, false},
+		{"GoodNoDeleteMoreLog", fields{db1, ""}, &ReclaimResult{[]ServiceLogReclaim{
+			{"hana01", 30003, "indexserver", 2, 1000, 3, 1100}}, preTime, postTime}, false},
+		{"GoodServiceChange", fields{db1, ""}, &ReclaimResult{[]ServiceLogReclaim{
+			{"hana01", 30003, "indexserver", 2, 1000, 0, 0},
+			{"hana02", 30040, "indexserver", 0, 0, 1, 500}}, preTime, postTime}, false},
+		{"GoodNoRows", fields{db1, ""}, &ReclaimResult{[]ServiceLogReclaim{}, preTime, postTime}, false},
+		{"2ndCurrentTimeDbError", fields{db1, ""}, nil, true},
+		{"2ndGetFreeLogSegmentsDbError", fields{db1, ""}, nil, true},
+		{"2ndGetFreeLogSegmentsScanError", fields{db1, ""}, nil, true},
+		{"ReclaimLogDbError", fields{db1, ""}, nil, true},
+		{"1stCurrentTimeDbError", fields{db1, ""}, nil, true},
+		{"1stGetFreeLogSegmentsDbError", fields{db1, ""}, nil, true},
+		{"1stGetFreeLogSegmentsScanError", fields{db1, ""}, nil, true},
 	}
 	for _, tt := range tests {
 		/*Set up per case mocking*/
 		switch tt.name {
 		case "GoodFullDelete":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			rows2 := sqlmock.NewRows([]string{"BYTES"}).AddRow(0)
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			rows1 := sqlmock.NewRows(cols)
+			rows1.AddRow("hana01", 30001, "nameserver", 2, 1000)
+			rows1.AddRow("hana01", 30003, "indexserver", 10, 5000)
+			rows2 := sqlmock.NewRows(cols)
+			rows2.AddRow("hana01", 30001, "nameserver", 0, 0)
+			rows2.AddRow("hana01", 30003, "indexserver", 0, 0)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(postTime))
 		case "GoodPartialDelete":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			rows2 := sqlmock.NewRows([]string{"BYTES"}).AddRow(100)
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 10, 1000)
+			rows2 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 1, 100)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(postTime))
 		case "GoodNoDelete":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			rows2 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			rows2 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(postTime))
 		case "GoodNoDeleteMoreLog":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			rows2 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1100)
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			rows2 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 3, 1100)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
+			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(postTime))
+		case "GoodServiceChange":
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			rows2 := sqlmock.NewRows(cols).AddRow("hana02", 30040, "indexserver", 1, 500)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows2)
-		case "2ndGetFreeLogBytesDbError":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(postTime))
+		case "GoodNoRows":
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(sqlmock.NewRows(cols))
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnError(fmt.Errorf("DbError"))
-		case "2ndGetFreeLogBytesScanError":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			rows2 := sqlmock.NewRows([]string{"BYTES"}).AddRow("1102.323")
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(sqlmock.NewRows(cols))
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(postTime))
+		case "2ndCurrentTimeDbError":
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			rows2 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 0, 0)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnError(fmt.Errorf("DbError"))
+		case "2ndGetFreeLogSegmentsDbError":
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
+			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnError(fmt.Errorf("DbError"))
+		case "2ndGetFreeLogSegmentsScanError":
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			rows2 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, "1102.323")
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
+			mock.ExpectExec(q_ReclaimLog).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows2)
 		case "ReclaimLogDbError":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow(1000)
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnRows(sqlmock.NewRows([]string{"CURRENT_TIME"}).AddRow(preTime))
 			mock.ExpectExec(q_ReclaimLog).WillReturnError(fmt.Errorf("DbError"))
-		case "1stGetFreeLogBytesScanError":
-			rows1 := sqlmock.NewRows([]string{"BYTES"}).AddRow("1000.34")
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnRows(rows1)
-		case "1stGetFreeLogBytesDbError":
-			mock.ExpectQuery(q_GetFreeLogBytes).WillReturnError(fmt.Errorf("DbError"))
+		case "1stCurrentTimeDbError":
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, 1000)
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+			mock.ExpectQuery(q_GetDbCurrentTime).WillReturnError(fmt.Errorf("DbError"))
+		case "1stGetFreeLogSegmentsScanError":
+			rows1 := sqlmock.NewRows(cols).AddRow("hana01", 30003, "indexserver", 2, "1000.34")
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnRows(rows1)
+		case "1stGetFreeLogSegmentsDbError":
+			mock.ExpectQuery(q_GetFreeLogSegmentsByService).WillReturnError(fmt.Errorf("DbError"))
 		default:
 			fmt.Printf("No test case matched for %s\n", tt.name)
 			t.Errorf("No test case matched")
@@ -472,9 +598,535 @@ func Test_hanaUtilClient_ReclaimLog(t *testing.T) {
 				t.Errorf("hanaUtilClient.ReclaimLog() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("hanaUtilClient.ReclaimLog() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestHanaUtilClient_MergeDelta(t *testing.T) {
+	/*Test Setup*/
+	/*Mock DB*/
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"PARTITIONS", "DELTA_SIZE", "DELTA_RECORDS"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		schema string
+		table  string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *MergeStats
+		wantErr bool
+	}{
+		{"GoodFullMerge", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, &MergeStats{10000, 40960}, false},
+		{"GoodPartialMerge", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, &MergeStats{9900, 40000}, false},
+		{"GoodDeltaGrew", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, &MergeStats{0, 0}, false},
+		{"2ndGetTableDeltaDbError", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, nil, true},
+		{"2ndGetTableDeltaScanError", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, nil, true},
+		{"MergeDeltaDbError", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, nil, true},
+		{"TableNotFound", fields{db1, ""}, args{"SAPABAP1", "NOTATABLE"}, nil, true},
+		{"1stGetTableDeltaDbError", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, nil, true},
+		{"1stGetTableDeltaScanError", fields{db1, ""}, args{"SAPABAP1", "BALDAT"}, nil, true},
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		q := f_GetTableDelta(tt.args.schema, tt.args.table)
+		switch tt.name {
+		case "GoodFullMerge":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 40960, 10000))
+			mock.ExpectExec(f_MergeDelta(tt.args.schema, tt.args.table)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 0, 0))
+		case "GoodPartialMerge":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(4, 40960, 10000))
+			mock.ExpectExec(f_MergeDelta(tt.args.schema, tt.args.table)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(4, 960, 100))
+		case "GoodDeltaGrew":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 40960, 10000))
+			mock.ExpectExec(f_MergeDelta(tt.args.schema, tt.args.table)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 50000, 12000))
+		case "2ndGetTableDeltaDbError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 40960, 10000))
+			mock.ExpectExec(f_MergeDelta(tt.args.schema, tt.args.table)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "2ndGetTableDeltaScanError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 40960, 10000))
+			mock.ExpectExec(f_MergeDelta(tt.args.schema, tt.args.table)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, "0.5", 0))
+		case "MergeDeltaDbError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 40960, 10000))
+			mock.ExpectExec(f_MergeDelta(tt.args.schema, tt.args.table)).WillReturnError(fmt.Errorf("DbError"))
+		case "TableNotFound":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow(0, 0, 0))
+		case "1stGetTableDeltaDbError":
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "1stGetTableDeltaScanError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows(cols).AddRow("one", 40960, 10000))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.MergeDelta(tt.args.schema, tt.args.table)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.MergeDelta() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.MergeDelta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_RemoveStatServerAlertsBatched(t *testing.T) {
+	/*Test Setup*/
+	/*Mock DB*/
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		days      uint
+		batchSize uint
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		want         *BatchDeleteStats
+		wantProgress []BatchProgress
+		wantErr      bool
+	}{
+		{"GoodSingleBatch", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{99}, 99},
+			[]BatchProgress{{1, 99, 99}}, false},
+		{"GoodMultipleBatches", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{100, 100, 20}, 220},
+			[]BatchProgress{{1, 100, 100}, {2, 100, 200}, {3, 20, 220}}, false},
+		{"GoodExactBatches", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{100, 0}, 100},
+			[]BatchProgress{{1, 100, 100}, {2, 0, 100}}, false},
+		{"GoodDefaultBatchSize", fields{db1, ""}, args{42, 0}, &BatchDeleteStats{[]uint64{5}, 5},
+			[]BatchProgress{{1, 5, 5}}, false},
+		{"Cancelled", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{100}, 100},
+			[]BatchProgress{{1, 100, 100}}, true},
+		{"2ndBatchExecError", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{100}, 100},
+			[]BatchProgress{{1, 100, 100}}, true},
+		{"CommitError", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{}, 0}, nil, true},
+		{"BeginError", fields{db1, ""}, args{42, 100}, &BatchDeleteStats{[]uint64{}, 0}, nil, true},
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		batchSize := tt.args.batchSize
+		if batchSize == 0 {
+			batchSize = DefaultBatchSize
+		}
+		q := f_RemoveStatServerAlertsBatch(tt.args.days, batchSize)
+		switch tt.name {
+		case "GoodSingleBatch":
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, 99))
+			mock.ExpectCommit()
+		case "GoodMultipleBatches":
+			for _, n := range []int64{100, 100, 20} {
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, n))
+				mock.ExpectCommit()
+			}
+		case "GoodExactBatches":
+			for _, n := range []int64{100, 0} {
+				mock.ExpectBegin()
+				mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, n))
+				mock.ExpectCommit()
+			}
+		case "GoodDefaultBatchSize":
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, 5))
+			mock.ExpectCommit()
+		case "Cancelled":
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, 100))
+			mock.ExpectCommit()
+		case "2ndBatchExecError":
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, 100))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WillReturnError(fmt.Errorf("DbError"))
+			mock.ExpectRollback()
+		case "CommitError":
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WillReturnResult(sqlmock.NewResult(0, 100))
+			mock.ExpectCommit().WillReturnError(fmt.Errorf("DbError"))
+		case "BeginError":
+			mock.ExpectBegin().WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var progress []BatchProgress
+			opts := BatchOptions{
+				BatchSize: tt.args.batchSize,
+				Progress: func(bp BatchProgress) {
+					progress = append(progress, bp)
+					if tt.name == "Cancelled" {
+						cancel()
+					}
+				},
+			}
+			got, err := h.RemoveStatServerAlertsBatched(ctx, tt.args.days, opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.RemoveStatServerAlertsBatched() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.RemoveStatServerAlertsBatched() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(progress, tt.wantProgress) {
+				t.Errorf("HanaUtilClient.RemoveStatServerAlertsBatched() progress = %v, want %v", progress, tt.wantProgress)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestHanaUtilClient_PurgeStatisticsHistory(t *testing.T) {
+	/*Test Setup*/
+	/*Mock DB*/
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"TABLE_NAME", "RECORD_COUNT", "TABLE_SIZE"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		days uint
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []StatisticsHistoryPurge
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, args{42}, []StatisticsHistoryPurge{
+			{"HOST_SQL_PLAN_CACHE_BASE", 1000000, 100000, 409600000, 40960000},
+			{"GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 5000, 204800, 204800}}, false},
+		{"GoodNoTables", fields{db1, ""}, args{42}, []StatisticsHistoryPurge{}, false},
+		{"2ndGetSizesDbError", fields{db1, ""}, args{42}, []StatisticsHistoryPurge{
+			{"HOST_SQL_PLAN_CACHE_BASE", 1000000, 995000, 409600000, 409600000}}, true},
+		{"PurgeDbError", fields{db1, ""}, args{42}, []StatisticsHistoryPurge{
+			{"HOST_SQL_PLAN_CACHE_BASE", 1000000, 990000, 409600000, 409600000},
+			{"GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 5000, 204800, 204800}}, true},
+		{"1stGetSizesDbError", fields{db1, ""}, args{42}, nil, true},
+	}
+	/*expectBatch expects a single committed batch removing 'rows' rows from
+	'table', or failing with 'err'*/
+	expectBatch := func(table string, days uint, rows int64, err error) {
+		mock.ExpectBegin()
+		e := mock.ExpectExec(f_PurgeStatisticsHistoryBatch(table, days, DefaultBatchSize))
+		if err != nil {
+			e.WillReturnError(err)
+			mock.ExpectRollback()
+			return
+		}
+		e.WillReturnResult(sqlmock.NewResult(0, rows))
+		mock.ExpectCommit()
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		switch tt.name {
+		case "Good":
+			rows1 := sqlmock.NewRows(cols)
+			rows1.AddRow("HOST_SQL_PLAN_CACHE_BASE", 1000000, 409600000)
+			rows1.AddRow("GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 204800)
+			rows2 := sqlmock.NewRows(cols)
+			rows2.AddRow("GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 204800)
+			rows2.AddRow("HOST_SQL_PLAN_CACHE_BASE", 100000, 40960000)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(rows1)
+			for i := 0; i < 90; i++ {
+				expectBatch("HOST_SQL_PLAN_CACHE_BASE", tt.args.days, int64(DefaultBatchSize), nil)
+			}
+			expectBatch("HOST_SQL_PLAN_CACHE_BASE", tt.args.days, 0, nil)
+			expectBatch("GLOBAL_ROWSTORE_TABLES_SIZE_BASE", tt.args.days, 0, nil)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(rows2)
+		case "GoodNoTables":
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(sqlmock.NewRows(cols))
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(sqlmock.NewRows(cols))
+		case "2ndGetSizesDbError":
+			rows1 := sqlmock.NewRows(cols).AddRow("HOST_SQL_PLAN_CACHE_BASE", 1000000, 409600000)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(rows1)
+			expectBatch("HOST_SQL_PLAN_CACHE_BASE", tt.args.days, 5000, nil)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnError(fmt.Errorf("DbError"))
+		case "PurgeDbError":
+			/*The first table is purged, the second fails on its first batch*/
+			rows1 := sqlmock.NewRows(cols)
+			rows1.AddRow("HOST_SQL_PLAN_CACHE_BASE", 1000000, 409600000)
+			rows1.AddRow("GLOBAL_ROWSTORE_TABLES_SIZE_BASE", 5000, 204800)
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnRows(rows1)
+			expectBatch("HOST_SQL_PLAN_CACHE_BASE", tt.args.days, int64(DefaultBatchSize), nil)
+			expectBatch("HOST_SQL_PLAN_CACHE_BASE", tt.args.days, 0, nil)
+			expectBatch("GLOBAL_ROWSTORE_TABLES_SIZE_BASE", tt.args.days, 0, fmt.Errorf("DbError"))
+		case "1stGetSizesDbError":
+			mock.ExpectQuery(q_GetStatisticsHistorySizes).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.PurgeStatisticsHistory(tt.args.days)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.PurgeStatisticsHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.PurgeStatisticsHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestHanaUtilClient_TruncateAuditLog(t *testing.T) {
+	/*Test Setup*/
+	/*Mock DB*/
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	//Generic timestamp
+	genTime := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	type args struct {
+		before time.Time
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    uint64
+		wantErr bool
+	}{
+		{"GoodAllDeleted", fields{db1, ""}, args{genTime}, 5000, false},
+		{"GoodSomeDeleted", fields{db1, ""}, args{genTime}, 4000, false},
+		{"NothingDeletedButMoreFound", fields{db1, ""}, args{genTime}, 0, false},
+		{"2ndGetAuditLogEntriesDbError", fields{db1, ""}, args{genTime}, 0, true},
+		{"ClearAuditLogDbError", fields{db1, ""}, args{genTime}, 0, true},
+		{"1stGetAuditLogEntriesDbError", fields{db1, ""}, args{genTime}, 0, true},
+		{"1stGetAuditLogEntriesScanError", fields{db1, ""}, args{genTime}, 0, true},
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		q := f_GetAuditLogEntriesBefore(tt.args.before)
+		switch tt.name {
+		case "GoodAllDeleted":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(5000))
+			mock.ExpectExec(f_ClearAuditLog(tt.args.before)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(0))
+		case "GoodSomeDeleted":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(5000))
+			mock.ExpectExec(f_ClearAuditLog(tt.args.before)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(1000))
+		case "NothingDeletedButMoreFound":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(5000))
+			mock.ExpectExec(f_ClearAuditLog(tt.args.before)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(5001))
+		case "2ndGetAuditLogEntriesDbError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(5000))
+			mock.ExpectExec(f_ClearAuditLog(tt.args.before)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "ClearAuditLogDbError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(5000))
+			mock.ExpectExec(f_ClearAuditLog(tt.args.before)).WillReturnError(fmt.Errorf("DbError"))
+		case "1stGetAuditLogEntriesDbError":
+			mock.ExpectQuery(q).WillReturnError(fmt.Errorf("DbError"))
+		case "1stGetAuditLogEntriesScanError":
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow("1.5"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.TruncateAuditLog(tt.args.before)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.TruncateAuditLog() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("HanaUtilClient.TruncateAuditLog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_ClearSqlPlanCache(t *testing.T) {
+	/*Test Setup*/
+	/*Mock DB*/
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"PLANS", "BYTES", "CAPACITY"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *ClearStats
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &ClearStats{4900, 1000000}, false},
+		{"GoodCacheGrew", fields{db1, ""}, &ClearStats{0, 0}, false},
+		{"2ndGetStatsDbError", fields{db1, ""}, nil, true},
+		{"ClearDbError", fields{db1, ""}, nil, true},
+		{"1stGetStatsDbError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		switch tt.name {
+		case "Good":
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(5000, 1024000, 4096000))
+			mock.ExpectExec(q_ClearSqlPlanCache).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(100, 24000, 4096000))
+		case "GoodCacheGrew":
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(10, 1000, 4096000))
+			mock.ExpectExec(q_ClearSqlPlanCache).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(20, 2000, 4096000))
+		case "2ndGetStatsDbError":
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(5000, 1024000, 4096000))
+			mock.ExpectExec(q_ClearSqlPlanCache).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnError(fmt.Errorf("DbError"))
+		case "ClearDbError":
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(5000, 1024000, 4096000))
+			mock.ExpectExec(q_ClearSqlPlanCache).WillReturnError(fmt.Errorf("DbError"))
+		case "1stGetStatsDbError":
+			mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.ClearSqlPlanCache()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.ClearSqlPlanCache() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.ClearSqlPlanCache() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_ClearExpensiveStatementsTrace(t *testing.T) {
+	/*Test Setup*/
+	/*Mock DB*/
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+	cols := []string{"ENTRIES", "BYTES"}
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *ClearStats
+		wantErr bool
+	}{
+		{"Good", fields{db1, ""}, &ClearStats{30000, 2000000}, false},
+		{"2ndGetStatsDbError", fields{db1, ""}, nil, true},
+		{"ClearDbError", fields{db1, ""}, nil, true},
+		{"1stGetStatsDbError", fields{db1, ""}, nil, true},
+	}
+	for _, tt := range tests {
+		/*Set up per case mocking*/
+		switch tt.name {
+		case "Good":
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(30000, 2048000))
+			mock.ExpectExec(q_ClearExpensiveStatementsTrace).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(0, 48000))
+		case "2ndGetStatsDbError":
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(30000, 2048000))
+			mock.ExpectExec(q_ClearExpensiveStatementsTrace).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnError(fmt.Errorf("DbError"))
+		case "ClearDbError":
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(30000, 2048000))
+			mock.ExpectExec(q_ClearExpensiveStatementsTrace).WillReturnError(fmt.Errorf("DbError"))
+		case "1stGetStatsDbError":
+			mock.ExpectQuery(q_GetExpensiveStatementsStats).WillReturnError(fmt.Errorf("DbError"))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			got, err := h.ClearExpensiveStatementsTrace()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.ClearExpensiveStatementsTrace() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HanaUtilClient.ClearExpensiveStatementsTrace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
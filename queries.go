@@ -1,6 +1,10 @@
 package hanautil
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 /******************************************************************************/
 /* The file contains all the queries used in the library.                     */
@@ -10,47 +14,113 @@ import "fmt"
 /* Functions that return a query string have the f_ naming convention         */
 /******************************************************************************/
 
+// Layout used to format timestamps in queries, timestamps are always given in
+// UTC
+const hanaTimestampFormat = "2006-01-02 15:04:05"
+
+// Returns 's' as a string literal, doubling any single quotes so that it
+// cannot end the literal. Used where HANA does not accept bind parameters.
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Returns 's' as a delimited identifier, doubling any double quotes so that it
+// cannot end the identifier
+func quoteIdentifier(s string) string {
+	return "\"" + strings.ReplaceAll(s, "\"", "\"\"") + "\""
+}
+
 const q_GetHanaVersion = "SELECT VERSION FROM \"SYS\".\"M_DATABASE\""
 
 const q_GetDbCurrentTime = "SELECT NOW() AS \"CURRENT_TIME\" FROM DUMMY"
 
-const q_GetBackupCatalogEntryCount = "SELECT " +
-	"COUNT(BACKUP_ID) AS COUNT " +
-	"FROM \"SYS\".\"M_BACKUP_CATALOG\""
-
-const q_GetBackupCount = "SELECT " +
-	"COUNT(ENTRY_ID) AS COUNT, " +
-	"ENTRY_TYPE_NAME " +
-	"FROM \"SYS\".\"M_BACKUP_CATALOG\" " +
-	"GROUP BY ENTRY_TYPE_NAME"
-
-const q_GetBackupSizes = "SELECT " +
-	"CAT.ENTRY_TYPE_NAME AS TYPE,  " +
-	"SUM(FILES.BACKUP_SIZE) AS BYTES  " +
-	"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT  " +
-	"LEFT JOIN \"SYS\".\"M_BACKUP_CATALOG_FILES\" AS FILES " +
-	"ON CAT.BACKUP_ID = FILES.BACKUP_ID  " +
-	"GROUP BY CAT.ENTRY_TYPE_NAME"
-
-const q_GetOldestBackups = "SELECT " +
-	"ENTRY_TYPE_NAME, " +
-	"MIN(UTC_START_TIME) AS UTC_START_NAME " +
-	"FROM \"SYS\".\"M_BACKUP_CATALOG\"" +
-	"WHERE " +
-	"ENTRY_TYPE_NAME = 'complete data backup' OR ENTRY_TYPE_NAME = 'log backup' " +
-	"GROUP BY ENTRY_TYPE_NAME"
-
-const q_GetBackupCatalogSize = "SELECT TOP 1 " +
-	"BF.BACKUP_SIZE " +
-	"FROM " +
-	"\"SYS\".\"M_BACKUP_CATALOG\" B, " +
-	"\"SYS\".\"M_BACKUP_CATALOG_FILES\" BF " +
-	"WHERE " +
-	"B.BACKUP_ID = BF.BACKUP_ID AND " +
-	"BF.SOURCE_TYPE_NAME = 'catalog' AND " +
-	"B.STATE_NAME = 'successful' " +
-	"ORDER BY " +
-	"B.SYS_START_TIME DESC; "
+// Returns the conditions used to filter backup catalog entries with columns of
+// the table 'alias'. An empty filter has no conditions.
+func backupFilterConditions(f BackupFilter, alias string) []string {
+	conds := make([]string, 0)
+	if f.BeforeBackupID != "" {
+		conds = append(conds, fmt.Sprintf("%s.BACKUP_ID < %s", alias, f.BeforeBackupID))
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, fmt.Sprintf("%s.UTC_START_TIME >= '%s'", alias, f.From.UTC().Format(hanaTimestampFormat)))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, fmt.Sprintf("%s.UTC_START_TIME < '%s'", alias, f.To.UTC().Format(hanaTimestampFormat)))
+	}
+	return conds
+}
+
+// Returns the WHERE clause used to filter backup catalog entries with columns
+// of the table 'alias'
+func backupFilterWhere(f BackupFilter, alias string) string {
+	conds := backupFilterConditions(f, alias)
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ") + " "
+}
+
+// Get the number, size and oldest and newest start times of the entries of
+// each backup type together with the size of the backup catalog and the
+// current time. The catalog is joined to DUMMY so that an empty catalog still
+// returns a single row, with a NULL type. Files are summed per backup before
+// the join so that backups of many files are counted once.
+func f_GetBackupSummary(f BackupFilter) string {
+	return "SELECT " +
+		"T.ENTRY_TYPE_NAME, " +
+		"COALESCE(T.ENTRIES,0) AS ENTRIES, " +
+		"COALESCE(T.BYTES,0) AS BYTES, " +
+		"T.OLDEST, " +
+		"T.NEWEST, " +
+		"COALESCE((" + f_GetBackupCatalogSize(f) + "),0) AS CATALOG_SIZE, " +
+		"CURRENT_UTCTIMESTAMP AS \"CURRENT_TIME\" " +
+		"FROM DUMMY " +
+		"LEFT JOIN (" +
+		"SELECT " +
+		"CAT.ENTRY_TYPE_NAME, " +
+		"COUNT(CAT.ENTRY_ID) AS ENTRIES, " +
+		"SUM(FILES.BYTES) AS BYTES, " +
+		"MIN(CAT.UTC_START_TIME) AS OLDEST, " +
+		"MAX(CAT.UTC_START_TIME) AS NEWEST " +
+		"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT " +
+		"LEFT JOIN (" +
+		"SELECT BACKUP_ID, SUM(BACKUP_SIZE) AS BYTES " +
+		"FROM \"SYS\".\"M_BACKUP_CATALOG_FILES\" " +
+		"GROUP BY BACKUP_ID" +
+		") AS FILES " +
+		"ON CAT.BACKUP_ID = FILES.BACKUP_ID " +
+		backupFilterWhere(f, "CAT") +
+		"GROUP BY CAT.ENTRY_TYPE_NAME" +
+		") AS T ON 1 = 1 " +
+		"ORDER BY T.ENTRY_TYPE_NAME"
+}
+
+// Get the size of the newest successful backup of the backup catalog
+func f_GetBackupCatalogSize(f BackupFilter) string {
+	conds := append([]string{
+		"CAT.BACKUP_ID = BF.BACKUP_ID",
+		"BF.SOURCE_TYPE_NAME = 'catalog'",
+		"CAT.STATE_NAME = 'successful'",
+	}, backupFilterConditions(f, "CAT")...)
+	return "SELECT TOP 1 " +
+		"BF.BACKUP_SIZE " +
+		"FROM " +
+		"\"SYS\".\"M_BACKUP_CATALOG\" AS CAT, " +
+		"\"SYS\".\"M_BACKUP_CATALOG_FILES\" AS BF " +
+		"WHERE " + strings.Join(conds, " AND ") + " " +
+		"ORDER BY " +
+		"CAT.SYS_START_TIME DESC"
+}
+
+const q_GetDbCurrentUtcTime = "SELECT CURRENT_UTCTIMESTAMP AS \"CURRENT_TIME\" FROM DUMMY"
+
+const q_GetLatestBackups string = "SELECT " +
+	"(SELECT MAX(UTC_START_TIME) FROM \"SYS\".\"M_BACKUP_CATALOG\" " +
+	"WHERE ENTRY_TYPE_NAME = 'complete data backup' AND STATE_NAME = 'successful') AS FULL_BACKUP, " +
+	"(SELECT MAX(UTC_START_TIME) FROM \"SYS\".\"M_BACKUP_CATALOG\" " +
+	"WHERE ENTRY_TYPE_NAME = 'log backup' AND STATE_NAME = 'successful') AS LOG_BACKUP, " +
+	"CURRENT_UTCTIMESTAMP AS \"CURRENT_TIME\" " +
+	"FROM DUMMY"
 
 const q_GetLogSegmentStats = "SELECT " +
 	"STATE, " +
@@ -69,13 +139,83 @@ const q_GetLogSegmentStats = "SELECT " +
 	"\"SYS\".\"M_LOG_SEGMENTS\" " +
 	"WHERE STATE != 'Free';"
 
-const q_GetFreeLogBytes string = "SELECT " +
-	"COALESCE(SUM(TOTAL_SIZE),0) AS BYTES " +
-	"FROM \"SYS\".\"M_LOG_SEGMENTS\" " +
-	"WHERE STATE = 'Free';"
+const q_GetFreeLogSegmentsByService string = "SELECT " +
+	"LS.HOST, " +
+	"LS.PORT, " +
+	"COALESCE(S.SERVICE_NAME, '') AS SERVICE_NAME, " +
+	"SUM(CASE WHEN LS.STATE = 'Free' THEN 1 ELSE 0 END) AS SEGMENTS, " +
+	"COALESCE(SUM(CASE WHEN LS.STATE = 'Free' THEN LS.TOTAL_SIZE ELSE 0 END),0) AS BYTES " +
+	"FROM \"SYS\".\"M_LOG_SEGMENTS\" AS LS " +
+	"LEFT JOIN \"SYS\".\"M_SERVICES\" AS S " +
+	"ON LS.HOST = S.HOST AND LS.PORT = S.PORT " +
+	"GROUP BY LS.HOST, LS.PORT, S.SERVICE_NAME " +
+	"ORDER BY LS.HOST, LS.PORT"
 
 const q_ReclaimLog string = "ALTER SYSTEM RECLAIM LOG"
 
+const q_GetHostMemory string = "SELECT " +
+	"HOST, " +
+	"INSTANCE_TOTAL_MEMORY_USED_SIZE, " +
+	"INSTANCE_TOTAL_MEMORY_PEAK_USED_SIZE, " +
+	"INSTANCE_TOTAL_MEMORY_ALLOCATED_SIZE, " +
+	"ALLOCATION_LIMIT " +
+	"FROM \"SYS\".\"M_HOST_RESOURCE_UTILIZATION\" " +
+	"ORDER BY HOST"
+
+const q_GetServiceMemory string = "SELECT " +
+	"SM.HOST, " +
+	"SM.PORT, " +
+	"SM.SERVICE_NAME, " +
+	"SM.TOTAL_MEMORY_USED_SIZE, " +
+	"COALESCE(LH.PEAK_MEMORY_USED, SM.TOTAL_MEMORY_USED_SIZE) AS PEAK_MEMORY_USED, " +
+	"SM.ALLOCATION_LIMIT, " +
+	"SM.EFFECTIVE_ALLOCATION_LIMIT " +
+	"FROM \"SYS\".\"M_SERVICE_MEMORY\" AS SM " +
+	"LEFT JOIN (" +
+	"SELECT HOST, PORT, MAX(MEMORY_USED) AS PEAK_MEMORY_USED " +
+	"FROM \"SYS\".\"M_LOAD_HISTORY_SERVICE\" " +
+	"GROUP BY HOST, PORT) AS LH " +
+	"ON SM.HOST = LH.HOST AND SM.PORT = LH.PORT " +
+	"ORDER BY SM.HOST, SM.PORT"
+
+const q_GetStatisticsHistorySizes string = "SELECT " +
+	"T.TABLE_NAME, " +
+	"T.RECORD_COUNT, " +
+	"T.TABLE_SIZE " +
+	"FROM \"SYS\".\"M_TABLES\" AS T " +
+	"INNER JOIN \"SYS\".\"TABLE_COLUMNS\" AS C " +
+	"ON T.SCHEMA_NAME = C.SCHEMA_NAME AND T.TABLE_NAME = C.TABLE_NAME " +
+	"WHERE T.SCHEMA_NAME = '_SYS_STATISTICS' " +
+	"AND C.COLUMN_NAME = 'SERVER_TIMESTAMP' " +
+	"AND (T.TABLE_NAME LIKE 'HOST\\_%' ESCAPE '\\' OR T.TABLE_NAME LIKE 'GLOBAL\\_%' ESCAPE '\\') " +
+	"ORDER BY T.TABLE_SIZE DESC"
+
+const q_GetAuditLogStats string = "SELECT " +
+	"COUNT(*) AS ENTRIES, " +
+	"MIN(\"TIMESTAMP\") AS OLDEST, " +
+	"MAX(\"TIMESTAMP\") AS NEWEST, " +
+	"(SELECT COALESCE(SUM(TABLE_SIZE),0) FROM \"SYS\".\"M_TABLES\" " +
+	"WHERE SCHEMA_NAME = '_SYS_AUDIT' AND TABLE_NAME = 'CS_AUDIT_LOG_') AS BYTES " +
+	"FROM \"SYS\".\"AUDIT_LOG\""
+
+const q_GetSqlPlanCacheStats string = "SELECT " +
+	"COALESCE(SUM(CACHED_PLAN_COUNT),0) AS PLANS, " +
+	"COALESCE(SUM(CACHED_PLAN_SIZE),0) AS BYTES, " +
+	"COALESCE(SUM(PLAN_CACHE_CAPACITY),0) AS CAPACITY " +
+	"FROM \"SYS\".\"M_SQL_PLAN_CACHE_OVERVIEW\""
+
+// Requires OPTIMIZER ADMIN priv
+const q_ClearSqlPlanCache string = "ALTER SYSTEM CLEAR SQL PLAN CACHE"
+
+const q_GetExpensiveStatementsStats string = "SELECT " +
+	"COUNT(*) AS ENTRIES, " +
+	"(SELECT COALESCE(SUM(EXCLUSIVE_SIZE_IN_USE),0) FROM \"SYS\".\"M_HEAP_MEMORY\" " +
+	"WHERE CATEGORY LIKE '%ExpensiveStatement%') AS BYTES " +
+	"FROM \"SYS\".\"M_EXPENSIVE_STATEMENTS\""
+
+// Requires TRACE ADMIN priv
+const q_ClearExpensiveStatementsTrace string = "ALTER SYSTEM CLEAR TRACES ('EXPENSIVESTATEMENT')"
+
 func q_GetLatestFullBackupID(days uint) string {
 	return fmt.Sprintf("SELECT "+
 		"BACKUP_ID "+
@@ -88,19 +228,36 @@ func q_GetLatestFullBackupID(days uint) string {
 		"ORDER BY SYS_END_TIME DESC LIMIT 1", days)
 }
 
-func f_GetTraceFile(host, filename string) string {
-	return fmt.Sprintf("SELECT COUNT(FILE_NAME) AS COUNT FROM \"SYS\".\"M_TRACEFILES\" WHERE HOST = '%s' AND FILE_NAME = '%s'", host, filename)
-}
+// Count the trace files with the host and file name given as bind parameters
+const q_GetTraceFile = "SELECT COUNT(FILE_NAME) AS COUNT FROM \"SYS\".\"M_TRACEFILES\" WHERE HOST = ? AND FILE_NAME = ?"
 
 func f_GetTraceFiles(days uint) string {
 	return fmt.Sprintf("SELECT HOST, FILE_NAME, FILE_SIZE, FILE_MTIME FROM \"SYS\".\"M_TRACEFILES\" WHERE FILE_MTIME < (SELECT ADD_DAYS(NOW(), -%d) FROM DUMMY) AND RIGHT(FILE_NAME, 3) = 'trc' OR FILE_MTIME < (SELECT ADD_DAYS(NOW(), -%d) FROM DUMMY) AND RIGHT(FILE_NAME, 2) = 'gz'", days, days)
 }
 
+// Returns a string query that lists the start time, in UTC, and size of every
+// successful backup of the backup catalog started in the last 'days' days
+func f_GetBackupCatalogHistory(days uint) string {
+	return fmt.Sprintf("SELECT "+
+		"B.UTC_START_TIME, "+
+		"BF.BACKUP_SIZE "+
+		"FROM "+
+		"\"SYS\".\"M_BACKUP_CATALOG\" B, "+
+		"\"SYS\".\"M_BACKUP_CATALOG_FILES\" BF "+
+		"WHERE "+
+		"B.BACKUP_ID = BF.BACKUP_ID AND "+
+		"BF.SOURCE_TYPE_NAME = 'catalog' AND "+
+		"B.STATE_NAME = 'successful' AND "+
+		"B.UTC_START_TIME >= ADD_DAYS(CURRENT_UTCTIMESTAMP, -%d) "+
+		"ORDER BY "+
+		"B.UTC_START_TIME", days)
+}
+
 // Returns a string query that is used to attempt to remove the identified trace
 // file
 // Require TRACE ADMIN priv
 func f_RemoveTraceFile(hostname, filename string) string {
-	return fmt.Sprintf("ALTER SYSTEM REMOVE TRACES('%s', '%s')", hostname, filename)
+	return fmt.Sprintf("ALTER SYSTEM REMOVE TRACES(%s, %s)", quoteString(hostname), quoteString(filename))
 }
 
 // Returns a string that is used to remove old backup catalog entries. This
@@ -140,29 +297,253 @@ func f_RemoveStatServerAlerts(days uint) string {
 		"WHERE ALERT_TIMESTAMP < ADD_DAYS(NOW(), -%d)", days)
 }
 
-func f_GetBackupCatalogEntryCountBeforeID(s string) string {
+const q_GetDiskUsage string = "SELECT " +
+	"D.HOST, " +
+	"D.PATH, " +
+	"D.USAGE_TYPE, " +
+	"D.FILESYSTEM_TYPE, " +
+	"D.TOTAL_SIZE, " +
+	"D.USED_SIZE, " +
+	"COALESCE(U.USED_SIZE,0) AS HANA_USED_SIZE " +
+	"FROM \"SYS\".\"M_DISKS\" AS D " +
+	"LEFT JOIN \"SYS\".\"M_DISK_USAGE\" AS U " +
+	"ON D.HOST = U.HOST AND D.USAGE_TYPE = U.USAGE_TYPE " +
+	"WHERE D.USAGE_TYPE IN ('DATA', 'LOG', 'TRACE', 'DATA_BACKUP', 'LOG_BACKUP') " +
+	"ORDER BY D.HOST, D.USAGE_TYPE"
+
+const q_GetVolumeUsage string = "SELECT " +
+	"HOST, " +
+	"PORT, " +
+	"VOLUME_ID, " +
+	"FILE_TYPE, " +
+	"FILE_NAME, " +
+	"TOTAL_SIZE, " +
+	"USED_SIZE " +
+	"FROM \"SYS\".\"M_VOLUME_FILES\" " +
+	"ORDER BY HOST, PORT, VOLUME_ID, FILE_TYPE"
+
+// Get the top n heap memory allocators across all services
+func f_GetTopHeapAllocators(n uint) string {
+	return fmt.Sprintf("SELECT TOP %d "+
+		"HOST, "+
+		"PORT, "+
+		"CATEGORY, "+
+		"EXCLUSIVE_SIZE_IN_USE "+
+		"FROM \"SYS\".\"M_HEAP_MEMORY\" "+
+		"ORDER BY EXCLUSIVE_SIZE_IN_USE DESC", n)
+}
+
+// Get the size of column and/or row store tables ordered by memory size. An
+// empty schema includes all schemas, an empty store includes both stores and a
+// limit of 0 includes all tables
+func f_GetTableSizes(schema, store string, limit uint) string {
+	var where, top string
+	if schema != "" {
+		where = "WHERE SCHEMA_NAME = " + quoteString(schema) + " "
+	}
+	if limit > 0 {
+		top = fmt.Sprintf("TOP %d ", limit)
+	}
+	cs := "SELECT " +
+		"CS.SCHEMA_NAME, " +
+		"CS.TABLE_NAME, " +
+		"'COLUMN' AS STORE_TYPE, " +
+		"SUM(CS.MEMORY_SIZE_IN_TOTAL) AS MEMORY_SIZE, " +
+		"COALESCE(MAX(PS.DISK_SIZE),0) AS DISK_SIZE, " +
+		"SUM(CS.RECORD_COUNT) AS RECORD_COUNT, " +
+		"SUM(CS.MEMORY_SIZE_IN_DELTA) AS DELTA_SIZE, " +
+		"COUNT(CS.PART_ID) AS PARTITIONS " +
+		"FROM \"SYS\".\"M_CS_TABLES\" AS CS " +
+		"LEFT JOIN \"SYS\".\"M_TABLE_PERSISTENCE_STATISTICS\" AS PS " +
+		"ON CS.SCHEMA_NAME = PS.SCHEMA_NAME AND CS.TABLE_NAME = PS.TABLE_NAME " +
+		"GROUP BY CS.SCHEMA_NAME, CS.TABLE_NAME"
+	rs := "SELECT " +
+		"RS.SCHEMA_NAME, " +
+		"RS.TABLE_NAME, " +
+		"'ROW' AS STORE_TYPE, " +
+		"SUM(RS.ALLOCATED_FIXED_PART_SIZE + RS.ALLOCATED_VARIABLE_PART_SIZE) AS MEMORY_SIZE, " +
+		"COALESCE(MAX(PS.DISK_SIZE),0) AS DISK_SIZE, " +
+		"SUM(RS.RECORD_COUNT) AS RECORD_COUNT, " +
+		"0 AS DELTA_SIZE, " +
+		"COUNT(*) AS PARTITIONS " +
+		"FROM \"SYS\".\"M_RS_TABLES\" AS RS " +
+		"LEFT JOIN \"SYS\".\"M_TABLE_PERSISTENCE_STATISTICS\" AS PS " +
+		"ON RS.SCHEMA_NAME = PS.SCHEMA_NAME AND RS.TABLE_NAME = PS.TABLE_NAME " +
+		"GROUP BY RS.SCHEMA_NAME, RS.TABLE_NAME"
+	var tables string
+	switch store {
+	case "COLUMN":
+		tables = cs
+	case "ROW":
+		tables = rs
+	default:
+		tables = cs + " UNION ALL " + rs
+	}
+	return fmt.Sprintf("SELECT %s"+
+		"SCHEMA_NAME, TABLE_NAME, STORE_TYPE, MEMORY_SIZE, DISK_SIZE, RECORD_COUNT, DELTA_SIZE, PARTITIONS "+
+		"FROM (%s) "+
+		"%s"+
+		"ORDER BY MEMORY_SIZE DESC", top, tables, where)
+}
+
+// Get the column store unloads that have occurred within the given number of
+// days
+func f_GetTableUnloads(days uint) string {
 	return fmt.Sprintf("SELECT "+
-		"COUNT(BACKUP_ID) AS COUNT "+
-		"FROM \"SYS\".\"M_BACKUP_CATALOG\""+
-		"WHERE BACKUP_ID < '%s'", s)
+		"UNLOAD_TIME, "+
+		"HOST, "+
+		"PORT, "+
+		"SCHEMA_NAME, "+
+		"TABLE_NAME, "+
+		"COALESCE(COLUMN_NAME, '') AS COLUMN_NAME, "+
+		"REASON "+
+		"FROM \"SYS\".\"M_CS_UNLOADS\" "+
+		"WHERE UNLOAD_TIME > ADD_DAYS(NOW(), -%d) "+
+		"ORDER BY UNLOAD_TIME DESC", days)
 }
 
-func f_GetBackupCountBeforeID(s string) string {
+// Get column store tables where the delta storage is at least minBytes in size
+// and holds at least minRecords records, largest delta first
+func f_GetDeltaMergeCandidates(minBytes, minRecords uint64) string {
 	return fmt.Sprintf("SELECT "+
-		"COUNT(ENTRY_ID) AS COUNT, "+
-		"ENTRY_TYPE_NAME "+
-		"FROM \"SYS\".\"M_BACKUP_CATALOG\" "+
-		"WHERE BACKUP_ID < '%s' "+
-		"GROUP BY ENTRY_TYPE_NAME", s)
+		"CS.SCHEMA_NAME, "+
+		"CS.TABLE_NAME, "+
+		"SUM(CS.MEMORY_SIZE_IN_DELTA) AS DELTA_SIZE, "+
+		"SUM(CS.RAW_RECORD_COUNT_IN_DELTA) AS DELTA_RECORDS, "+
+		"SUM(CS.MEMORY_SIZE_IN_MAIN) AS MAIN_SIZE, "+
+		"MS.LAST_MERGE_TIME, "+
+		"COALESCE(MS.FAILED_MERGES,0) AS FAILED_MERGES "+
+		"FROM \"SYS\".\"M_CS_TABLES\" AS CS "+
+		"LEFT JOIN ("+
+		"SELECT SCHEMA_NAME, TABLE_NAME, "+
+		"MAX(CASE WHEN SUCCESS = 'TRUE' THEN START_TIME END) AS LAST_MERGE_TIME, "+
+		"SUM(CASE WHEN SUCCESS = 'FALSE' THEN 1 ELSE 0 END) AS FAILED_MERGES "+
+		"FROM \"SYS\".\"M_DELTA_MERGE_STATISTICS\" "+
+		"WHERE TYPE = 'MERGE' "+
+		"GROUP BY SCHEMA_NAME, TABLE_NAME) AS MS "+
+		"ON CS.SCHEMA_NAME = MS.SCHEMA_NAME AND CS.TABLE_NAME = MS.TABLE_NAME "+
+		"GROUP BY CS.SCHEMA_NAME, CS.TABLE_NAME, MS.LAST_MERGE_TIME, MS.FAILED_MERGES "+
+		"HAVING SUM(CS.MEMORY_SIZE_IN_DELTA) >= %d AND SUM(CS.RAW_RECORD_COUNT_IN_DELTA) >= %d "+
+		"ORDER BY DELTA_SIZE DESC", minBytes, minRecords)
+}
+
+// Get the number of partitions and the delta size of a single column store
+// table
+func f_GetTableDelta(schema, table string) string {
+	return fmt.Sprintf("SELECT "+
+		"COUNT(PART_ID) AS PARTITIONS, "+
+		"COALESCE(SUM(MEMORY_SIZE_IN_DELTA),0) AS DELTA_SIZE, "+
+		"COALESCE(SUM(RAW_RECORD_COUNT_IN_DELTA),0) AS DELTA_RECORDS "+
+		"FROM \"SYS\".\"M_CS_TABLES\" "+
+		"WHERE SCHEMA_NAME = %s AND TABLE_NAME = %s", quoteString(schema), quoteString(table))
+}
+
+// Statement to merge the delta storage of a column store table into main
+func f_MergeDelta(schema, table string) string {
+	return fmt.Sprintf("MERGE DELTA OF %s.%s", quoteIdentifier(schema), quoteIdentifier(table))
+}
+
+// Returns the local time of the database equivalent to 't', for comparison
+// with columns such as ALERT_TIMESTAMP that hold local rather than UTC times.
+// The offset is that of the database when the statement runs.
+func localTimestamp(t time.Time) string {
+	return fmt.Sprintf("ADD_SECONDS('%s', SECONDS_BETWEEN(CURRENT_UTCTIMESTAMP, NOW()))", t.UTC().Format(hanaTimestampFormat))
 }
 
-func f_GetBackupSizesBeforeId(s string) string {
+// Returns the WHERE clause used to filter statistics server alerts by minimum
+// rating and time range. A rating of 0 and zero times are not filtered upon.
+// Alert timestamps are in the local time of the database, so the range is
+// converted to it.
+func statServerAlertConditions(minRating uint, from, to time.Time) string {
+	conds := make([]string, 0)
+	if minRating > 0 {
+		conds = append(conds, fmt.Sprintf("A.ALERT_RATING >= %d", minRating))
+	}
+	if !from.IsZero() {
+		conds = append(conds, "A.ALERT_TIMESTAMP >= "+localTimestamp(from))
+	}
+	if !to.IsZero() {
+		conds = append(conds, "A.ALERT_TIMESTAMP < "+localTimestamp(to))
+	}
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ") + " "
+}
+
+// Get the statistics server alerts matching the given rating and time range,
+// newest first. A limit of 0 returns all alerts
+func f_ListStatServerAlerts(minRating uint, from, to time.Time, limit uint) string {
+	var top string
+	if limit > 0 {
+		top = fmt.Sprintf("TOP %d ", limit)
+	}
+	return fmt.Sprintf("SELECT %s"+
+		"A.ALERT_ID, "+
+		"COALESCE(I.ALERT_NAME, '') AS ALERT_NAME, "+
+		"A.ALERT_RATING, "+
+		"COALESCE(A.HOST, '') AS HOST, "+
+		"COALESCE(A.PORT, 0) AS PORT, "+
+		"A.ALERT_TIMESTAMP, "+
+		"COALESCE(A.ALERT_DETAILS, '') AS ALERT_DETAILS, "+
+		"COALESCE(A.ALERT_USERACTION, '') AS ALERT_USERACTION "+
+		"FROM \"_SYS_STATISTICS\".\"STATISTICS_ALERTS_BASE\" AS A "+
+		"LEFT JOIN \"_SYS_STATISTICS\".\"STATISTICS_ALERT_INFORMATION\" AS I "+
+		"ON A.ALERT_ID = I.ALERT_ID "+
+		"%s"+
+		"ORDER BY A.ALERT_TIMESTAMP DESC", top, statServerAlertConditions(minRating, from, to))
+}
+
+// Get a summary of the statistics server alerts matching the given rating and
+// time range grouped by alert ID
+func f_GetStatServerAlertSummary(minRating uint, from, to time.Time) string {
 	return fmt.Sprintf("SELECT "+
-		"CAT.ENTRY_TYPE_NAME AS TYPE, "+
-		"SUM(FILES.BACKUP_SIZE) AS BYTES "+
-		"FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT "+
-		"LEFT JOIN \"SYS\".\"M_BACKUP_CATALOG_FILES\" AS FILES "+
-		"ON CAT.BACKUP_ID = FILES.BACKUP_ID "+
-		"WHERE CAT.BACKUP_ID < '%s' "+
-		"GROUP BY CAT.ENTRY_TYPE_NAME", s)
+		"A.ALERT_ID, "+
+		"COALESCE(I.ALERT_NAME, '') AS ALERT_NAME, "+
+		"COUNT(A.SNAPSHOT_ID) AS COUNT, "+
+		"MAX(A.ALERT_RATING) AS MAX_RATING, "+
+		"MIN(A.ALERT_TIMESTAMP) AS FIRST_TIMESTAMP, "+
+		"MAX(A.ALERT_TIMESTAMP) AS LAST_TIMESTAMP "+
+		"FROM \"_SYS_STATISTICS\".\"STATISTICS_ALERTS_BASE\" AS A "+
+		"LEFT JOIN \"_SYS_STATISTICS\".\"STATISTICS_ALERT_INFORMATION\" AS I "+
+		"ON A.ALERT_ID = I.ALERT_ID "+
+		"%s"+
+		"GROUP BY A.ALERT_ID, I.ALERT_NAME "+
+		"ORDER BY MAX_RATING DESC, COUNT DESC", statServerAlertConditions(minRating, from, to))
+}
+
+// statement to remove at most batchSize alerts older than the given number of
+// days. HANA does not support a row limit on DELETE, so rows are selected by
+// their internal row ID
+func f_RemoveStatServerAlertsBatch(days, batchSize uint) string {
+	return fmt.Sprintf("DELETE FROM "+
+		"\"_SYS_STATISTICS\".\"STATISTICS_ALERTS_BASE\" "+
+		"WHERE \"$rowid$\" IN ("+
+		"SELECT TOP %d \"$rowid$\" "+
+		"FROM \"_SYS_STATISTICS\".\"STATISTICS_ALERTS_BASE\" "+
+		"WHERE ALERT_TIMESTAMP < ADD_DAYS(NOW(), -%d))", batchSize, days)
+}
+
+// statement to remove a single batch of at most 'batchSize' rows of statistics
+// server collector history older than the given number of days from a single
+// _SYS_STATISTICS table
+func f_PurgeStatisticsHistoryBatch(table string, days, batchSize uint) string {
+	return fmt.Sprintf("DELETE FROM "+
+		"\"_SYS_STATISTICS\".%s "+
+		"WHERE \"$rowid$\" IN ("+
+		"SELECT TOP %d \"$rowid$\" "+
+		"FROM \"_SYS_STATISTICS\".%s "+
+		"WHERE SERVER_TIMESTAMP < ADD_DAYS(NOW(), -%d))", quoteIdentifier(table), batchSize, quoteIdentifier(table), days)
+}
+
+// Get the number of audit log entries older than the given time
+func f_GetAuditLogEntriesBefore(before time.Time) string {
+	return fmt.Sprintf("SELECT COUNT(*) AS COUNT FROM \"SYS\".\"AUDIT_LOG\" "+
+		"WHERE \"TIMESTAMP\" < '%s'", before.UTC().Format(hanaTimestampFormat))
+}
+
+// statement to remove audit log entries older than the given time from the
+// audit log table. Requires AUDIT ADMIN or AUDIT OPERATOR priv
+func f_ClearAuditLog(before time.Time) string {
+	return fmt.Sprintf("ALTER SYSTEM CLEAR AUDIT LOG UNTIL '%s'", before.UTC().Format(hanaTimestampFormat))
 }
@@ -1,6 +1,10 @@
 package hanautil
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func Test_f_GetTraceFiles(t *testing.T) {
 	type args struct {
@@ -28,3 +32,187 @@ func Test_f_GetTraceFiles(t *testing.T) {
 		})
 	}
 }
+
+func Test_f_RemoveTraceFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		filename string
+		want     string
+	}{
+		{"Good", "hana01", "indexserver_hana01.30003.000.trc", "ALTER SYSTEM REMOVE TRACES('hana01', 'indexserver_hana01.30003.000.trc')"},
+		{"Quotes", "hana01", "x') ; --", "ALTER SYSTEM REMOVE TRACES('hana01', 'x'') ; --')"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f_RemoveTraceFile(tt.host, tt.filename); got != tt.want {
+				t.Errorf("f_RemoveTraceFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_f_MergeDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		table     string
+		wantMerge string
+		wantDelta string
+	}{
+		{"Good", "SAPABAP1", "BALDAT", `MERGE DELTA OF "SAPABAP1"."BALDAT"`,
+			`WHERE SCHEMA_NAME = 'SAPABAP1' AND TABLE_NAME = 'BALDAT'`},
+		{"DoubleQuotes", "SAPABAP1", `X"; DROP TABLE "Y`, `MERGE DELTA OF "SAPABAP1"."X""; DROP TABLE ""Y"`,
+			`WHERE SCHEMA_NAME = 'SAPABAP1' AND TABLE_NAME = 'X"; DROP TABLE "Y'`},
+		{"SingleQuotes", "S'", "T' OR '1'='1", `MERGE DELTA OF "S'"."T' OR '1'='1"`,
+			`WHERE SCHEMA_NAME = 'S''' AND TABLE_NAME = 'T'' OR ''1''=''1'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f_MergeDelta(tt.schema, tt.table); got != tt.wantMerge {
+				t.Errorf("f_MergeDelta() = %v, want %v", got, tt.wantMerge)
+			}
+			if got := f_GetTableDelta(tt.schema, tt.table); !strings.HasSuffix(got, tt.wantDelta) {
+				t.Errorf("f_GetTableDelta() = %v, want suffix %v", got, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func Test_f_PurgeStatisticsHistoryBatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		want  string
+	}{
+		{"Good", "HOST_WORKLOAD", `DELETE FROM "_SYS_STATISTICS"."HOST_WORKLOAD" WHERE "$rowid$" IN (SELECT TOP 1000 "$rowid$" FROM "_SYS_STATISTICS"."HOST_WORKLOAD" WHERE SERVER_TIMESTAMP < ADD_DAYS(NOW(), -42))`},
+		{"DoubleQuotes", `X"; DROP TABLE "Y`, `DELETE FROM "_SYS_STATISTICS"."X""; DROP TABLE ""Y" WHERE "$rowid$" IN (SELECT TOP 1000 "$rowid$" FROM "_SYS_STATISTICS"."X""; DROP TABLE ""Y" WHERE SERVER_TIMESTAMP < ADD_DAYS(NOW(), -42))`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f_PurgeStatisticsHistoryBatch(tt.table, 42, 1000); got != tt.want {
+				t.Errorf("f_PurgeStatisticsHistoryBatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_f_GetTableSizes(t *testing.T) {
+	type args struct {
+		schema string
+		store  string
+		limit  uint
+	}
+	tests := []struct {
+		name        string
+		args        args
+		contains    []string
+		notContains []string
+	}{
+		{"AllTables", args{"", "", 0},
+			[]string{"M_CS_TABLES", "UNION ALL", "M_RS_TABLES"},
+			[]string{"TOP", "WHERE"}},
+		{"Top10", args{"", "", 10},
+			[]string{"SELECT TOP 10 ", "UNION ALL"},
+			[]string{"WHERE"}},
+		{"ColumnSchema", args{"SAPABAP1", "COLUMN", 5},
+			[]string{"SELECT TOP 5 ", "M_CS_TABLES", "WHERE SCHEMA_NAME = 'SAPABAP1' "},
+			[]string{"M_RS_TABLES", "UNION ALL"}},
+		{"Row", args{"", "ROW", 0},
+			[]string{"M_RS_TABLES"},
+			[]string{"M_CS_TABLES", "UNION ALL"}},
+		{"SchemaQuote", args{"S' OR '1'='1", "", 0},
+			[]string{"WHERE SCHEMA_NAME = 'S'' OR ''1''=''1' "},
+			[]string{"WHERE SCHEMA_NAME = 'S' OR"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := f_GetTableSizes(tt.args.schema, tt.args.store, tt.args.limit)
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("f_GetTableSizes() = %v, want to contain %v", got, s)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(got, s) {
+					t.Errorf("f_GetTableSizes() = %v, want not to contain %v", got, s)
+				}
+			}
+		})
+	}
+}
+
+func Test_statServerAlertConditions(t *testing.T) {
+	from := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 8, 13, 0, 0, 0, time.FixedZone("CET", 3600))
+	type args struct {
+		minRating uint
+		from      time.Time
+		to        time.Time
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{"NoFilter", args{0, time.Time{}, time.Time{}}, ""},
+		{"Rating", args{4, time.Time{}, time.Time{}}, "WHERE A.ALERT_RATING >= 4 "},
+		{"From", args{0, from, time.Time{}}, "WHERE A.ALERT_TIMESTAMP >= ADD_SECONDS('2022-01-01 12:00:00', SECONDS_BETWEEN(CURRENT_UTCTIMESTAMP, NOW())) "},
+		{"All", args{3, from, to}, "WHERE A.ALERT_RATING >= 3 AND " +
+			"A.ALERT_TIMESTAMP >= ADD_SECONDS('2022-01-01 12:00:00', SECONDS_BETWEEN(CURRENT_UTCTIMESTAMP, NOW())) AND " +
+			"A.ALERT_TIMESTAMP < ADD_SECONDS('2022-01-08 12:00:00', SECONDS_BETWEEN(CURRENT_UTCTIMESTAMP, NOW())) "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statServerAlertConditions(tt.args.minRating, tt.args.from, tt.args.to); got != tt.want {
+				t.Errorf("statServerAlertConditions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_backupFilterQueries(t *testing.T) {
+	from := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 8, 13, 0, 0, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name string
+		f    BackupFilter
+		want string
+	}{
+		{"All", BackupFilter{}, ""},
+		{"BeforeID", BackupFilter{BeforeBackupID: "123"}, "CAT.BACKUP_ID < 123"},
+		{"From", BackupFilter{From: from}, "CAT.UTC_START_TIME >= '2022-01-01 12:00:00'"},
+		{"FromTo", BackupFilter{From: from, To: to}, "CAT.UTC_START_TIME >= '2022-01-01 12:00:00' AND CAT.UTC_START_TIME < '2022-01-08 12:00:00'"},
+		{"Everything", BackupFilter{"123", from, to}, "CAT.BACKUP_ID < 123 AND CAT.UTC_START_TIME >= '2022-01-01 12:00:00' AND CAT.UTC_START_TIME < '2022-01-08 12:00:00'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where := ""
+			if tt.want != "" {
+				where = "WHERE " + tt.want + " "
+			}
+			if got := backupFilterWhere(tt.f, "CAT"); got != where {
+				t.Errorf("backupFilterWhere() = %q, want %q", got, where)
+			}
+			/*Every sub-query of a summary applies the filter*/
+			for name, q := range map[string]string{
+				"f_GetBackupSummary":     f_GetBackupSummary(tt.f),
+				"f_GetBackupCatalogSize": f_GetBackupCatalogSize(tt.f),
+			} {
+				if !strings.Contains(q, tt.want) {
+					t.Errorf("%s() = %v, want to contain %v", name, q, tt.want)
+				}
+			}
+			/*The entries and the catalog size are both filtered*/
+			if tt.want != "" && strings.Count(f_GetBackupSummary(tt.f), tt.want) != 2 {
+				t.Errorf("f_GetBackupSummary() = %v, want %v twice", f_GetBackupSummary(tt.f), tt.want)
+			}
+		})
+	}
+	want := "SELECT TOP 1 BF.BACKUP_SIZE FROM \"SYS\".\"M_BACKUP_CATALOG\" AS CAT, \"SYS\".\"M_BACKUP_CATALOG_FILES\" AS BF " +
+		"WHERE CAT.BACKUP_ID = BF.BACKUP_ID AND BF.SOURCE_TYPE_NAME = 'catalog' AND CAT.STATE_NAME = 'successful' AND CAT.BACKUP_ID < 123 " +
+		"ORDER BY CAT.SYS_START_TIME DESC"
+	if got := f_GetBackupCatalogSize(BackupFilter{BeforeBackupID: "123"}); got != want {
+		t.Errorf("f_GetBackupCatalogSize() = %v, want %v", got, want)
+	}
+}
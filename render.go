@@ -0,0 +1,247 @@
+package hanautil
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+/******************************************************************************/
+/* This file contains renderers for the return types of the library. Every   */
+/* return type has json and yaml tags using a snake_case schema, fields that  */
+/* hold a size in bytes always include "bytes" in their name.                 */
+/******************************************************************************/
+
+// FormatBytes returns 'b' as a human readable size using binary units, for
+// example 1536 is returned as "1.50 KiB". Sizes below 1 KiB are returned as a
+// whole number of bytes.
+func FormatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// renderField is a single field of a struct that can be rendered
+type renderField struct {
+	index []int
+	name  string
+	bytes bool
+}
+
+// renderFields returns the fields of the struct type 't' that can be rendered.
+// Fields are named by their json tag. The fields of nested structs, other than
+// times, are rendered in place of the struct and named after it, for example
+// "full_backup_dates_oldest". Fields tagged "-", unexported fields and fields
+// that are not scalars, times or structs are not rendered.
+func renderFields(t reflect.Type) []renderField {
+	return appendRenderFields(make([]renderField, 0), t, nil, "")
+}
+
+// appendRenderFields appends the fields of the struct type 't' that can be
+// rendered to 'rf'. The 'index' and 'prefix' of a nested struct are those of
+// the field holding it.
+func appendRenderFields(rf []renderField, t reflect.Type, index []int, prefix string) []renderField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		name = prefix + name
+		fi := append(append(make([]int, 0, len(index)+1), index...), i)
+		switch f.Type.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.Interface, reflect.Pointer:
+			continue
+		case reflect.Struct:
+			if f.Type != reflect.TypeOf(time.Time{}) {
+				rf = appendRenderFields(rf, f.Type, fi, name+"_")
+				continue
+			}
+		}
+		rf = append(rf, renderField{index: fi, name: name, bytes: isBytesField(name)})
+	}
+	return rf
+}
+
+// isBytesField returns true if the json 'name' of a field shows it holds a
+// size in bytes
+func isBytesField(name string) bool {
+	for _, s := range strings.Split(name, "_") {
+		if s == "bytes" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderValue returns the string form of 'v'. Times are returned in RFC 3339
+// format in UTC with the zero time returned as an empty string. If 'human' is
+// set, byte fields are returned using FormatBytes.
+func renderValue(v reflect.Value, f renderField, human bool) string {
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if human && f.bytes {
+			return FormatBytes(v.Uint())
+		}
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	default:
+		return v.String()
+	}
+}
+
+// renderRows returns the renderable fields and the struct values held by 'v',
+// which must be a struct, a pointer to a struct or a slice of either. The
+// boolean return is true if 'v' is a slice.
+func renderRows(v any) ([]renderField, []reflect.Value, bool, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil, false, fmt.Errorf("NilValue")
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return renderFields(rv.Type()), []reflect.Value{rv}, false, nil
+	case reflect.Slice:
+		et := rv.Type().Elem()
+		ptr := et.Kind() == reflect.Pointer
+		if ptr {
+			et = et.Elem()
+		}
+		if et.Kind() != reflect.Struct {
+			return nil, nil, false, fmt.Errorf("UnsupportedType")
+		}
+		rows := make([]reflect.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			e := rv.Index(i)
+			if ptr {
+				if e.IsNil() {
+					continue
+				}
+				e = e.Elem()
+			}
+			rows = append(rows, e)
+		}
+		return renderFields(et), rows, true, nil
+	default:
+		return nil, nil, false, fmt.Errorf("UnsupportedType")
+	}
+}
+
+// WriteCSV writes 'v' to 'w' as CSV with a header row holding the json names
+// of the fields. 'v' must be a struct, a pointer to a struct or a slice of
+// either. Only scalar and time fields are written, nested slices such as the
+// Services of a ReclaimResult can be written by passing them directly. Sizes
+// are written in bytes and times in RFC 3339 format.
+func WriteCSV(w io.Writer, v any) error {
+	rf, rows, _, err := renderRows(v)
+	if err != nil {
+		/*PromoteError*/
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(rf))
+	for _, f := range rf {
+		header = append(header, f.name)
+	}
+	err = cw.Write(header)
+	if err != nil {
+		/*PromoteError*/
+		return err
+	}
+	for _, r := range rows {
+		rec := make([]string, 0, len(rf))
+		for _, f := range rf {
+			rec = append(rec, renderValue(r.FieldByIndex(f.index), f, false))
+		}
+		err = cw.Write(rec)
+		if err != nil {
+			/*PromoteError*/
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tableHeading returns the heading used for a field in a table. Byte fields
+// are shown with human readable sizes, so a trailing "bytes" is shown as
+// "size", or dropped when the name already ends in "size". This keeps the
+// heading of a size apart from that of the count it belongs to, for example
+// "FULL BACKUPS" and "FULL BACKUPS SIZE".
+func tableHeading(f renderField) string {
+	words := strings.Split(f.name, "_")
+	if n := len(words); f.bytes && n > 1 && words[n-1] == "bytes" {
+		words[n-1] = "size"
+		if words[n-2] == "size" {
+			words = words[:n-1]
+		}
+	}
+	return strings.ToUpper(strings.Join(words, " "))
+}
+
+// WriteTable writes 'v' to 'w' as an aligned, human readable table. 'v' must
+// be a struct, a pointer to a struct or a slice of either. A slice is written
+// with one row per element, a single struct is written with one row per field.
+// Sizes are written using FormatBytes.
+func WriteTable(w io.Writer, v any) error {
+	rf, rows, list, err := renderRows(v)
+	if err != nil {
+		/*PromoteError*/
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if list {
+		header := make([]string, 0, len(rf))
+		for _, f := range rf {
+			header = append(header, tableHeading(f))
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, r := range rows {
+			rec := make([]string, 0, len(rf))
+			for _, f := range rf {
+				rec = append(rec, renderValue(r.FieldByIndex(f.index), f, true))
+			}
+			fmt.Fprintln(tw, strings.Join(rec, "\t"))
+		}
+	} else {
+		for _, f := range rf {
+			fmt.Fprintf(tw, "%s\t%s\n", tableHeading(f), renderValue(rows[0].FieldByIndex(f.index), f, true))
+		}
+	}
+	return tw.Flush()
+}
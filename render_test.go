@@ -0,0 +1,160 @@
+package hanautil
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		b    uint64
+		want string
+	}{
+		{"Zero", 0, "0 B"},
+		{"Bytes", 1023, "1023 B"},
+		{"KiB", 1536, "1.50 KiB"},
+		{"MiB", 1048576, "1.00 MiB"},
+		{"GiB", 5 * 1073741824, "5.00 GiB"},
+		{"TiB", 1099511627776 + 549755813888, "1.50 TiB"},
+		{"Max", ^uint64(0), "16.00 EiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatBytes(tt.b); got != tt.want {
+				t.Errorf("FormatBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceFile_JSON(t *testing.T) {
+	tf := TraceFile{"hana01", "indexserver.trc", 1024, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	want := `{"hostname":"hana01","file_name":"indexserver.trc","file_size_bytes":1024,"last_modified":"2024-01-02T03:04:05Z"}`
+	got, err := json.Marshal(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t1 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		v       any
+		want    string
+		wantErr bool
+	}{
+		{"Slice", []TraceFile{{"hana01", "a.trc", 1024, t1}, {"hana02", "b,c.trc", 2048, time.Time{}}},
+			"hostname,file_name,file_size_bytes,last_modified\nhana01,a.trc,1024,2024-01-02T03:04:05Z\nhana02,\"b,c.trc\",2048,\n", false},
+		{"Pointer", &TruncateStats{10, 4096}, "files_removed,bytes_removed\n10,4096\n", false},
+		{"PointerSlice", []*TruncateStats{{1, 2}, nil}, "files_removed,bytes_removed\n1,2\n", false},
+		{"NestedSkipped", &ReclaimResult{Services: []ServiceLogReclaim{{Hostname: "hana01"}}, BeforeTime: t1, AfterTime: t1},
+			"before_time,after_time\n2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n", false},
+		{"FuncSkipped", BatchOptions{BatchSize: 10, Progress: func(BatchProgress) {}}, "batch_size\n10\n", false},
+		{"NilPointer", (*TruncateStats)(nil), "", true},
+		{"Unsupported", []uint64{1}, "", true},
+		{"Map", map[string]uint64{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := WriteCSV(&b, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("WriteCSV() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		want    string
+		wantErr bool
+	}{
+		{"Slice", []StatisticsHistoryPurge{{"HOST_WORKLOAD", 10, 5, 2048, 1024}},
+			"TABLE NAME     ROWS BEFORE  ROWS AFTER  BYTES BEFORE  BYTES AFTER\n" +
+				"HOST_WORKLOAD  10           5           2.00 KiB      1.00 KiB\n", false},
+		{"Struct", &LogSegmentsStats{2, 1073741824, 3, 100},
+			"FREE SEGMENTS          2\nFREE SEGMENT SIZE      1.00 GiB\nNON FREE SEGMENTS      3\nNON FREE SEGMENT SIZE  100 B\n", false},
+		{"EmptySlice", []TruncateStats{}, "FILES REMOVED  BYTES REMOVED\n", false},
+		{"SizeField", []TraceFile{{"hana01", "a.trc", 1024, time.Time{}}},
+			"HOSTNAME  FILE NAME  FILE SIZE  LAST MODIFIED\nhana01    a.trc      1.00 KiB   \n", false},
+		{"Unsupported", "text", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := WriteTable(&b, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteTable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("WriteTable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteBackupSummary(t *testing.T) {
+	t1 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	bs := &BackupSummary{FullBackups: 2, SizeOfFullBackupsBytes: 2048,
+		FullBackupDates: BackupDates{Oldest: t1, Newest: t1, OldestAgeSeconds: 60}}
+
+	var b bytes.Buffer
+	err := WriteTable(&b, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"\nFULL BACKUPS  ", "\nFULL BACKUPS SIZE  ", "\nFULL BACKUP DATES OLDEST  ", "\nFULL BACKUP DATES OLDEST AGE SECONDS  "} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteTable() = %q, want to contain %q", b.String(), want)
+		}
+	}
+
+	b.Reset()
+	err = WriteCSV(&b, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec) != 2 {
+		t.Fatalf("WriteCSV() wrote %d records, want 2", len(rec))
+	}
+	got := make(map[string]string)
+	for i, h := range rec[0] {
+		got[h] = rec[1][i]
+	}
+	want := map[string]string{
+		"full_backups":                         "2",
+		"full_backups_bytes":                   "2048",
+		"full_backup_dates_oldest":             "2024-01-02T03:04:05Z",
+		"full_backup_dates_oldest_age_seconds": "60",
+		"log_backup_dates_newest":              "",
+	}
+	for h, v := range want {
+		if g, ok := got[h]; !ok || g != v {
+			t.Errorf("WriteCSV() %s = %q, want %q", h, g, v)
+		}
+	}
+	if _, ok := got["types"]; ok {
+		t.Errorf("WriteCSV() rendered the types map")
+	}
+}
@@ -0,0 +1,363 @@
+// Package server provides an HTTP handler that exposes the operations of a
+// hanautil client as a JSON REST API, so that applications can use hanautil
+// without linking the library.
+//
+// Every request must carry one of the configured bearer tokens in its
+// Authorization header. Responses are the JSON form of the hanautil return
+// types. Errors are returned as {"error": "..."} with an appropriate status.
+//
+// Read endpoints:
+//
+//	GET  /version                      {"version": "..."}
+//	GET  /traces?days=N                []hanautil.TraceFile
+//	GET  /backups/summary              hanautil.BackupSummary
+//	GET  /backups/latest               hanautil.LatestBackups
+//	GET  /alerts?days=N                {"count": N}
+//	GET  /log/segments                 hanautil.LogSegmentsStats
+//	GET  /disks                        []hanautil.DiskUsage
+//	GET  /volumes                      []hanautil.VolumeUsage
+//	GET  /memory?top=N                 hanautil.MemoryUsage
+//	POST /housekeeping/plan            hanautil.HousekeepingPlan
+//
+// Destructive endpoints, which must be called with confirm=true:
+//
+//	POST /traces/remove?host=H&file=F  {"host": "...", "file_name": "..."}
+//	POST /backups/truncate?days=N      hanautil.TruncateStats
+//	POST /alerts/remove?days=N         {"removed": N}
+//	POST /log/reclaim                  hanautil.ReclaimResult
+//	POST /housekeeping/apply           hanautil.HousekeepingReport
+//
+// Only trace files listed by GET /traces can be removed, others are reported as
+// not found. POST /backups/truncate also accepts complete=true, which destroys the backup
+// files as well as removing them from the catalog. The housekeeping endpoints
+// take a hanautil.HousekeepingPolicy as their JSON body, in which every enabled
+// destructive area must give its retention in days. The apply endpoint
+// plans the policy itself rather than accepting a plan from the caller.
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mr-stringer/hanautil"
+)
+
+// Options configures the server. Tokens holds the bearer tokens accepted by
+// the server, at least one is required.
+type Options struct {
+	Tokens []string
+}
+
+// server serves the API of a single client
+type server struct {
+	c      hanautil.Client
+	tokens [][]byte
+}
+
+// NewHandler returns an http.Handler that serves the API for 'c', which is
+// usually a connected *hanautil.HanaUtilClient. 'c' must be safe for
+// concurrent use, as HanaUtilClient is.
+func NewHandler(c hanautil.Client, opts Options) (http.Handler, error) {
+	if len(opts.Tokens) == 0 {
+		return nil, fmt.Errorf("NoTokens")
+	}
+	s := &server{c: c}
+	for _, t := range opts.Tokens {
+		if t == "" {
+			return nil, fmt.Errorf("EmptyToken")
+		}
+		s.tokens = append(s.tokens, []byte(t))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /version", s.version)
+	mux.HandleFunc("GET /traces", s.traces)
+	mux.HandleFunc("GET /backups/summary", s.backupSummary)
+	mux.HandleFunc("GET /backups/latest", s.latestBackups)
+	mux.HandleFunc("GET /alerts", s.alerts)
+	mux.HandleFunc("GET /log/segments", s.logSegments)
+	mux.HandleFunc("GET /disks", s.disks)
+	mux.HandleFunc("GET /volumes", s.volumes)
+	mux.HandleFunc("GET /memory", s.memory)
+	mux.HandleFunc("POST /housekeeping/plan", s.housekeepingPlan)
+
+	mux.HandleFunc("POST /traces/remove", confirmed(s.removeTrace))
+	mux.HandleFunc("POST /backups/truncate", confirmed(s.truncateBackups))
+	mux.HandleFunc("POST /alerts/remove", confirmed(s.removeAlerts))
+	mux.HandleFunc("POST /log/reclaim", confirmed(s.reclaimLog))
+	mux.HandleFunc("POST /housekeeping/apply", confirmed(s.housekeepingApply))
+
+	return s.authenticate(mux), nil
+}
+
+// authenticate rejects requests that do not carry a known bearer token
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !s.validToken([]byte(token)) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="hanautil"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("Unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validToken returns true if 'token' is one of the configured tokens. Every
+// token is compared in constant time.
+func (s *server) validToken(token []byte) bool {
+	valid := 0
+	for _, t := range s.tokens {
+		valid |= subtle.ConstantTimeCompare(t, token)
+	}
+	return valid == 1
+}
+
+// confirmed rejects requests to destructive endpoints that are not made with
+// confirm=true
+func confirmed(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("confirm") != "true" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("ConfirmationRequired: call again with confirm=true"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeJSON writes 'v' as the JSON response body with status 'code'
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes 'err' as a JSON error response with status 'code'
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+// writeResult writes 'v' or, if 'err' is set, an error response. Errors from
+// the client are reported as internal server errors except for a missing trace
+// file, which is reported as not found.
+func writeResult(w http.ResponseWriter, v any, err error) {
+	if err != nil {
+		code := http.StatusInternalServerError
+		if err.Error() == "TraceFileNotFound" {
+			code = http.StatusNotFound
+		}
+		writeError(w, code, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// uintParam returns the query parameter 'name' of 'r' as an unsigned integer.
+// If the parameter is not set 'def' is returned.
+func uintParam(r *http.Request, name string, def uint) (uint, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("InvalidParameter: %s must be a whole number", name)
+	}
+	return uint(n), nil
+}
+
+// requiredUintParam returns the query parameter 'name' of 'r', which must be
+// set, as an unsigned integer
+func requiredUintParam(r *http.Request, name string) (uint, error) {
+	if r.URL.Query().Get(name) == "" {
+		return 0, fmt.Errorf("MissingParameter: %s", name)
+	}
+	return uintParam(r, name, 0)
+}
+
+// readPolicy reads the housekeeping policy from the body of 'r'. Each enabled
+// destructive area must state its retention explicitly, a missing retention
+// would otherwise be read as zero days and remove everything.
+func readPolicy(r *http.Request) (hanautil.HousekeepingPolicy, error) {
+	var p hanautil.HousekeepingPolicy
+	var raw json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&raw)
+	if err != nil {
+		return p, fmt.Errorf("InvalidPolicy: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&p)
+	if err != nil {
+		return p, fmt.Errorf("InvalidPolicy: %w", err)
+	}
+	var set map[string]json.RawMessage
+	err = json.Unmarshal(raw, &set)
+	if err != nil {
+		return p, fmt.Errorf("InvalidPolicy: %w", err)
+	}
+	for _, a := range []struct {
+		enabled   bool
+		retention string
+	}{
+		{p.TraceFiles, "trace_retention_days"},
+		{p.BackupCatalog, "backup_retention_days"},
+		{p.StatServerAlerts, "alert_retention_days"},
+	} {
+		if _, ok := set[a.retention]; a.enabled && !ok {
+			return p, fmt.Errorf("InvalidPolicy: %s must be set", a.retention)
+		}
+	}
+	return p, nil
+}
+
+func (s *server) version(w http.ResponseWriter, r *http.Request) {
+	v, err := s.c.GetVersion()
+	writeResult(w, struct {
+		Version string `json:"version"`
+	}{v}, err)
+}
+
+func (s *server) traces(w http.ResponseWriter, r *http.Request) {
+	days, err := uintParam(r, "days", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	tf, err := s.c.GetTraceFiles(days)
+	writeResult(w, tf, err)
+}
+
+func (s *server) backupSummary(w http.ResponseWriter, r *http.Request) {
+	bs, err := s.c.GetBackupSummary()
+	writeResult(w, bs, err)
+}
+
+func (s *server) latestBackups(w http.ResponseWriter, r *http.Request) {
+	lb, err := s.c.GetLatestBackups()
+	writeResult(w, lb, err)
+}
+
+func (s *server) alerts(w http.ResponseWriter, r *http.Request) {
+	days, err := uintParam(r, "days", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	n, err := s.c.GetStatServerAlerts(days)
+	writeResult(w, struct {
+		Count uint `json:"count"`
+	}{n}, err)
+}
+
+func (s *server) logSegments(w http.ResponseWriter, r *http.Request) {
+	ls, err := s.c.GetLogSegmentStats()
+	writeResult(w, ls, err)
+}
+
+func (s *server) disks(w http.ResponseWriter, r *http.Request) {
+	du, err := s.c.GetDiskUsage()
+	writeResult(w, du, err)
+}
+
+func (s *server) volumes(w http.ResponseWriter, r *http.Request) {
+	vu, err := s.c.GetVolumeUsage()
+	writeResult(w, vu, err)
+}
+
+func (s *server) memory(w http.ResponseWriter, r *http.Request) {
+	top, err := uintParam(r, "top", 10)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	mu, err := s.c.GetMemoryUsage(top)
+	writeResult(w, mu, err)
+}
+
+func (s *server) housekeepingPlan(w http.ResponseWriter, r *http.Request) {
+	p, err := readPolicy(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	plan, err := s.c.PlanHousekeeping(p)
+	writeResult(w, plan, err)
+}
+
+func (s *server) removeTrace(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Query().Get("host")
+	file := r.URL.Query().Get("file")
+	if host == "" || file == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("MissingParameter: host and file"))
+		return
+	}
+	/*Only files that GET /traces would list may be removed*/
+	tf, err := s.c.GetTraceFiles(0)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+	if !slices.ContainsFunc(tf, func(t hanautil.TraceFile) bool {
+		return t.Hostname == host && t.FileName == file
+	}) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("TraceFileNotFound"))
+		return
+	}
+	err = s.c.RemoveTraceFile(host, file)
+	writeResult(w, struct {
+		Host     string `json:"host"`
+		FileName string `json:"file_name"`
+	}{host, file}, err)
+}
+
+func (s *server) truncateBackups(w http.ResponseWriter, r *http.Request) {
+	days, err := requiredUintParam(r, "days")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	complete := r.URL.Query().Get("complete") == "true"
+	ts, err := s.c.TruncateBackupCatalog(int(days), complete)
+	writeResult(w, ts, err)
+}
+
+func (s *server) removeAlerts(w http.ResponseWriter, r *http.Request) {
+	days, err := requiredUintParam(r, "days")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	n, err := s.c.RemoveStatServerAlerts(days)
+	writeResult(w, struct {
+		Removed uint64 `json:"removed"`
+	}{n}, err)
+}
+
+func (s *server) reclaimLog(w http.ResponseWriter, r *http.Request) {
+	rr, err := s.c.ReclaimLog()
+	writeResult(w, rr, err)
+}
+
+func (s *server) housekeepingApply(w http.ResponseWriter, r *http.Request) {
+	p, err := readPolicy(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	plan, err := s.c.PlanHousekeeping(p)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+	report, err := s.c.ApplyHousekeeping(plan)
+	writeResult(w, report, err)
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mr-stringer/hanautil"
+	"github.com/mr-stringer/hanautil/hanautiltest"
+)
+
+var genTime = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+const testToken = "s3cret"
+
+func newFake() *hanautiltest.Fake {
+	return &hanautiltest.Fake{
+		Now:     genTime,
+		Version: "2.00.070.00",
+		TraceFiles: []hanautiltest.TraceFile{
+			{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "old.trc", FileSizeBytes: 100, LastModified: genTime.AddDate(0, 0, -30)}},
+			{TraceFile: hanautil.TraceFile{Hostname: "hana01", FileName: "new.trc", FileSizeBytes: 300, LastModified: genTime}},
+		},
+		LogSegments: []hanautiltest.LogSegment{
+			{Hostname: "hana01", Port: 30003, ServiceName: "indexserver", Free: true, SizeBytes: 1024},
+		},
+	}
+}
+
+func newTestServer(t *testing.T, c hanautil.Client) *httptest.Server {
+	t.Helper()
+	h, err := NewHandler(c, Options{Tokens: []string{"other", testToken}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// do makes a request to 'ts' with the test token and decodes the response
+// body into 'v'
+func do(t *testing.T, ts *httptest.Server, method, path, body string, v any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s Content-Type = %s", method, path, ct)
+		}
+		err = json.NewDecoder(resp.Body).Decode(v)
+		if err != nil {
+			t.Fatalf("%s %s returned invalid JSON: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestNewHandler(t *testing.T) {
+	_, err := NewHandler(newFake(), Options{})
+	if err == nil {
+		t.Errorf("NewHandler() without tokens returned no error")
+	}
+	_, err = NewHandler(newFake(), Options{Tokens: []string{""}})
+	if err == nil {
+		t.Errorf("NewHandler() with an empty token returned no error")
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	ts := newTestServer(t, newFake())
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"NoHeader", "", http.StatusUnauthorized},
+		{"WrongScheme", "Basic " + testToken, http.StatusUnauthorized},
+		{"WrongToken", "Bearer nope", http.StatusUnauthorized},
+		{"Valid", "Bearer " + testToken, http.StatusOK},
+		{"OtherToken", "Bearer other", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+"/version", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("no WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestReadEndpoints(t *testing.T) {
+	f := newFake()
+	ts := newTestServer(t, f)
+
+	var v struct {
+		Version string `json:"version"`
+	}
+	if code := do(t, ts, http.MethodGet, "/version", "", &v); code != http.StatusOK || v.Version != "2.00.070.00" {
+		t.Errorf("GET /version = %d, %+v", code, v)
+	}
+
+	var tf []hanautil.TraceFile
+	if code := do(t, ts, http.MethodGet, "/traces?days=7", "", &tf); code != http.StatusOK || len(tf) != 1 || tf[0].FileName != "old.trc" {
+		t.Errorf("GET /traces = %d, %+v", code, tf)
+	}
+
+	var e struct {
+		Error string `json:"error"`
+	}
+	if code := do(t, ts, http.MethodGet, "/traces?days=-1", "", &e); code != http.StatusBadRequest || e.Error == "" {
+		t.Errorf("GET /traces?days=-1 = %d, %+v", code, e)
+	}
+
+	var ls hanautil.LogSegmentsStats
+	if code := do(t, ts, http.MethodGet, "/log/segments", "", &ls); code != http.StatusOK || ls.FreeSegments != 1 {
+		t.Errorf("GET /log/segments = %d, %+v", code, ls)
+	}
+
+	var plan hanautil.HousekeepingPlan
+	if code := do(t, ts, http.MethodPost, "/housekeeping/plan", `{"trace_files": true, "trace_retention_days": 7}`, &plan); code != http.StatusOK || plan.PredictedEntries() != 1 {
+		t.Errorf("POST /housekeeping/plan = %d, %+v", code, plan)
+	}
+	if code := do(t, ts, http.MethodPost, "/housekeeping/plan", `{"trace_file": true}`, &e); code != http.StatusBadRequest {
+		t.Errorf("POST /housekeeping/plan with an unknown field = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	f.Errors = map[string]error{"GetBackupSummary": errors.New("SQLError")}
+	if code := do(t, ts, http.MethodGet, "/backups/summary", "", &e); code != http.StatusInternalServerError || e.Error != "SQLError" {
+		t.Errorf("GET /backups/summary = %d, %+v", code, e)
+	}
+
+	if code := do(t, ts, http.MethodDelete, "/version", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE /version = %d, want %d", code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestDestructiveEndpoints(t *testing.T) {
+	f := newFake()
+	ts := newTestServer(t, f)
+
+	var e struct {
+		Error string `json:"error"`
+	}
+	for _, path := range []string{"/traces/remove?host=hana01&file=old.trc", "/backups/truncate?days=7", "/alerts/remove?days=7", "/log/reclaim", "/housekeeping/apply", "/log/reclaim?confirm=1"} {
+		if code := do(t, ts, http.MethodPost, path, "", &e); code != http.StatusBadRequest || !strings.HasPrefix(e.Error, "ConfirmationRequired") {
+			t.Errorf("POST %s = %d, %+v", path, code, e)
+		}
+	}
+	if len(f.TraceFiles) != 2 || len(f.LogSegments) != 1 {
+		t.Fatalf("unconfirmed requests changed the database")
+	}
+
+	if code := do(t, ts, http.MethodPost, "/traces/remove?confirm=true&host=hana01&file=old.trc", "", nil); code != http.StatusOK {
+		t.Errorf("POST /traces/remove = %d", code)
+	}
+	if len(f.TraceFiles) != 1 {
+		t.Errorf("%d trace files left, want 1", len(f.TraceFiles))
+	}
+	if code := do(t, ts, http.MethodPost, "/traces/remove?confirm=true&host=hana01&file=old.trc", "", &e); code != http.StatusNotFound {
+		t.Errorf("POST /traces/remove of a removed file = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := do(t, ts, http.MethodPost, "/traces/remove?confirm=true&host=hana01&file=new.trc", "", &e); code != http.StatusNotFound {
+		t.Errorf("POST /traces/remove of an unlisted file = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := do(t, ts, http.MethodPost, "/traces/remove?confirm=true&host=hana01&file=x%27)%20%3B%20--", "", &e); code != http.StatusNotFound {
+		t.Errorf("POST /traces/remove of an unlisted file with a quote = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := do(t, ts, http.MethodPost, "/backups/truncate?confirm=true", "", &e); code != http.StatusBadRequest {
+		t.Errorf("POST /backups/truncate without days = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var rr hanautil.ReclaimResult
+	if code := do(t, ts, http.MethodPost, "/log/reclaim?confirm=true", "", &rr); code != http.StatusOK || len(rr.Services) != 1 {
+		t.Errorf("POST /log/reclaim = %d, %+v", code, rr)
+	}
+	if len(f.LogSegments) != 0 {
+		t.Errorf("%d log segments left, want 0", len(f.LogSegments))
+	}
+
+	var report hanautil.HousekeepingReport
+	if code := do(t, ts, http.MethodPost, "/housekeeping/apply?confirm=true", `{"stat_server_alerts": true, "alert_retention_days": 7}`, &report); code != http.StatusOK || len(report.Steps) != 1 {
+		t.Errorf("POST /housekeeping/apply = %d, %+v", code, report)
+	}
+}
+
+func TestHousekeepingRetentionRequired(t *testing.T) {
+	f := newFake()
+	ts := newTestServer(t, f)
+
+	var e struct {
+		Error string `json:"error"`
+	}
+	for _, body := range []string{
+		`{"trace_files": true}`,
+		`{"backup_catalog": true, "complete": true}`,
+		`{"stat_server_alerts": true, "trace_files": true, "trace_retention_days": 7}`,
+	} {
+		for _, path := range []string{"/housekeeping/plan", "/housekeeping/apply?confirm=true"} {
+			if code := do(t, ts, http.MethodPost, path, body, &e); code != http.StatusBadRequest || !strings.HasPrefix(e.Error, "InvalidPolicy") {
+				t.Errorf("POST %s %s = %d, %+v", path, body, code, e)
+			}
+		}
+	}
+	if len(f.TraceFiles) != 2 {
+		t.Fatalf("a policy without retention removed trace files")
+	}
+
+	/*A retention of zero days is allowed when it is explicit*/
+	var report hanautil.HousekeepingReport
+	if code := do(t, ts, http.MethodPost, "/housekeeping/apply?confirm=true", `{"trace_files": true, "trace_retention_days": 0, "reclaim_log": true}`, &report); code != http.StatusOK {
+		t.Errorf("POST /housekeeping/apply with an explicit retention = %d, %+v", code, report)
+	}
+}
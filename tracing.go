@@ -0,0 +1,154 @@
+package hanautil
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+/******************************************************************************/
+/* This file contains the optional OpenTelemetry instrumentation. Every       */
+/* operation of the client is a span, each query run by the operation is a    */
+/* child span named after the query constant or function in queries.go.       */
+/******************************************************************************/
+
+// instrumentationName is the name of the tracer used by the client
+const instrumentationName = "github.com/mr-stringer/hanautil"
+
+// SetTracerProvider enables tracing of the client using spans from 'tp'. Each
+// operation, such as TruncateBackupCatalog, is recorded as a span with a child
+// span for each query it runs. Query spans carry the SQL statement and, for
+// statements that change the database, the number of rows affected. An
+// operation called by another operation, such as those called by
+// ApplyHousekeeping, is recorded as a child span of that operation. Passing
+// nil disables tracing, which is the default.
+func (h *HanaUtilClient) SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		h.tracer = nil
+		return
+	}
+	h.tracer = tp.Tracer(instrumentationName)
+}
+
+// startSpan starts the span of an operation named 'name'. Operations of a
+// client returned by withParent are started as children of its parent.
+func (h *HanaUtilClient) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := h.tracer
+	if tracer == nil {
+		tracer = noop.Tracer{}
+	}
+	if h.parent != nil && !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		ctx = trace.ContextWithSpan(ctx, h.parent)
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// withParent returns a copy of the client whose operations are recorded as
+// children of the span of the operation in 'ctx'
+func (h *HanaUtilClient) withParent(ctx context.Context) *HanaUtilClient {
+	c := *h
+	c.parent = trace.SpanFromContext(ctx)
+	return &c
+}
+
+// startQuerySpan starts the child span of a query named 'name'
+func (h *HanaUtilClient) startQuerySpan(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	return h.startSpan(ctx, name,
+		attribute.String("db.system", "hana"),
+		attribute.String("db.statement", query),
+	)
+}
+
+// spanError records 'err' on 'span' and on the span of the operation in 'ctx'
+// and returns it unchanged
+func spanError(ctx context.Context, span trace.Span, err error) error {
+	if err == nil {
+		return nil
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	trace.SpanFromContext(ctx).SetStatus(codes.Error, err.Error())
+	return err
+}
+
+// row is a single row returned by queryRow. Errors of a single row query,
+// including sql.ErrNoRows, are returned by Scan, so the span of the query ends
+// when the row is scanned.
+type row struct {
+	ctx  context.Context
+	span trace.Span
+	r    *sql.Row
+}
+
+// Scan copies the columns of the row into 'dest' as sql.Row.Scan and records
+// any error on the span of the query
+func (r *row) Scan(dest ...any) error {
+	defer r.span.End()
+	return spanError(r.ctx, r.span, r.r.Scan(dest...))
+}
+
+// queryRow runs the query 'q' named 'name', with any bind parameters 'args',
+// as a child span of the operation in 'ctx'. The row must be scanned.
+func (h *HanaUtilClient) queryRow(ctx context.Context, name, q string, args ...any) *row {
+	qctx, span := h.startQuerySpan(ctx, name, q)
+	return &row{ctx: ctx, span: span, r: h.db.QueryRowContext(qctx, q, args...)}
+}
+
+// rows are the rows returned by query. The span of the query stays open while
+// the rows are read and ends when they are closed, errors returned by Scan, Err
+// and Close are recorded on it.
+type rows struct {
+	*sql.Rows
+	ctx  context.Context
+	span trace.Span
+}
+
+// Scan copies the columns of the current row into 'dest' as sql.Rows.Scan and
+// records any error on the span of the query
+func (r *rows) Scan(dest ...any) error {
+	return spanError(r.ctx, r.span, r.Rows.Scan(dest...))
+}
+
+// Err returns the error, if any, encountered while iterating over the rows and
+// records it on the span of the query
+func (r *rows) Err() error {
+	return spanError(r.ctx, r.span, r.Rows.Err())
+}
+
+// Close closes the rows and ends the span of the query
+func (r *rows) Close() error {
+	defer r.span.End()
+	return spanError(r.ctx, r.span, r.Rows.Close())
+}
+
+// query runs the query 'q' named 'name' as a child span of the operation in
+// 'ctx'. The span ends when the rows are closed, which the caller must do.
+func (h *HanaUtilClient) query(ctx context.Context, name, q string) (*rows, error) {
+	qctx, span := h.startQuerySpan(ctx, name, q)
+	r, err := h.db.QueryContext(qctx, q)
+	if err != nil {
+		defer span.End()
+		return nil, spanError(ctx, span, err)
+	}
+	return &rows{Rows: r, ctx: ctx, span: span}, nil
+}
+
+// exec runs the statement 'q' named 'name' as a child span of the operation in
+// 'ctx' and records the number of rows affected
+func (h *HanaUtilClient) exec(ctx context.Context, name, q string) (sql.Result, error) {
+	qctx, span := h.startQuerySpan(ctx, name, q)
+	defer span.End()
+	res, err := h.db.ExecContext(qctx, q)
+	if err != nil {
+		return nil, spanError(ctx, span, err)
+	}
+	/*Not all statements report rows affected*/
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", n))
+	}
+	return res, nil
+}
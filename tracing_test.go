@@ -0,0 +1,261 @@
+package hanautil
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHanaUtilClient_SetTracerProvider(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	type fields struct {
+		db  *sql.DB
+		dsn string
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		wantSpans  []string
+		wantStatus codes.Code
+		wantErr    bool
+	}{
+		{"Good", fields{db1, ""}, []string{"q_GetLatestFullBackupID", "f_GetTruncateData", "f_GetBackupDelete", "f_GetTruncateData", "TruncateBackupCatalog"}, codes.Unset, false},
+		{"DeleteError", fields{db1, ""}, []string{"q_GetLatestFullBackupID", "f_GetTruncateData", "f_GetBackupDelete", "TruncateBackupCatalog"}, codes.Error, true},
+		{"QueryError", fields{db1, ""}, []string{"q_GetLatestFullBackupID", "TruncateBackupCatalog"}, codes.Error, true},
+		{"NoFullBackup", fields{db1, ""}, []string{"q_GetLatestFullBackupID", "TruncateBackupCatalog"}, codes.Error, true},
+	}
+	for _, tt := range tests {
+		/*per case mocking*/
+		switch tt.name {
+		case "Good":
+			mock.ExpectQuery(q_GetLatestFullBackupID(0)).WillReturnRows(mock.NewRows([]string{"BACKUP_ID"}).AddRow("1000"))
+			mock.ExpectQuery(f_GetTruncateData("1000")).WillReturnRows(mock.NewRows([]string{"FILES", "BYTES"}).AddRow(10, 1024))
+			mock.ExpectExec(f_GetBackupDelete("1000")).WillReturnResult(sqlmock.NewResult(0, 10))
+			mock.ExpectQuery(f_GetTruncateData("1000")).WillReturnRows(mock.NewRows([]string{"FILES", "BYTES"}).AddRow(0, 0))
+		case "DeleteError":
+			mock.ExpectQuery(q_GetLatestFullBackupID(0)).WillReturnRows(mock.NewRows([]string{"BACKUP_ID"}).AddRow("1000"))
+			mock.ExpectQuery(f_GetTruncateData("1000")).WillReturnRows(mock.NewRows([]string{"FILES", "BYTES"}).AddRow(10, 1024))
+			mock.ExpectExec(f_GetBackupDelete("1000")).WillReturnError(fmt.Errorf("DbError"))
+		case "QueryError":
+			mock.ExpectQuery(q_GetLatestFullBackupID(0)).WillReturnError(fmt.Errorf("DbError"))
+		case "NoFullBackup":
+			/*sql.ErrNoRows is only returned by Scan*/
+			mock.ExpectQuery(q_GetLatestFullBackupID(0)).WillReturnRows(mock.NewRows([]string{"BACKUP_ID"}))
+		default:
+			fmt.Printf("No test case matched for %s\n", tt.name)
+			t.Errorf("No test case matched")
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			h := &HanaUtilClient{
+				db:  tt.fields.db,
+				dsn: tt.fields.dsn,
+			}
+			h.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+			_, err := h.TruncateBackupCatalog(0, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HanaUtilClient.TruncateBackupCatalog() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			spans := sr.Ended()
+			if len(spans) != len(tt.wantSpans) {
+				t.Fatalf("got %d spans, want %d", len(spans), len(tt.wantSpans))
+			}
+			op := spans[len(spans)-1]
+			for i, s := range spans {
+				if s.Name() != tt.wantSpans[i] {
+					t.Errorf("span %d = %s, want %s", i, s.Name(), tt.wantSpans[i])
+				}
+				if i < len(spans)-1 && s.Parent().SpanID() != op.SpanContext().SpanID() {
+					t.Errorf("span %s is not a child of %s", s.Name(), op.Name())
+				}
+			}
+			if op.Status().Code != tt.wantStatus {
+				t.Errorf("operation status = %v, want %v", op.Status().Code, tt.wantStatus)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := map[attribute.Key]attribute.Value{
+				"hana.backup_id":     attribute.StringValue("1000"),
+				"hana.files_removed": attribute.Int64Value(10),
+			}
+			for _, a := range op.Attributes() {
+				if v, ok := want[a.Key]; ok && v != a.Value {
+					t.Errorf("attribute %s = %v, want %v", a.Key, a.Value.Emit(), v.Emit())
+				}
+				delete(want, a.Key)
+			}
+			if len(want) != 0 {
+				t.Errorf("missing attributes %v", want)
+			}
+			var affected bool
+			for _, a := range spans[2].Attributes() {
+				if a.Key == "db.rows_affected" && a.Value.AsInt64() == 10 {
+					affected = true
+				}
+			}
+			if !affected {
+				t.Errorf("f_GetBackupDelete span has no db.rows_affected of 10")
+			}
+		})
+	}
+}
+
+func TestHanaUtilClient_SetTracerProviderNil(t *testing.T) {
+	h := NewClient("")
+	h.SetTracerProvider(sdktrace.NewTracerProvider())
+	if h.tracer == nil {
+		t.Errorf("SetTracerProvider() did not set a tracer")
+	}
+	h.SetTracerProvider(nil)
+	if h.tracer != nil {
+		t.Errorf("SetTracerProvider(nil) did not disable tracing")
+	}
+}
+
+func TestHanaUtilClient_HousekeepingSpans(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	h := &HanaUtilClient{db: db1}
+	h.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	policy := HousekeepingPolicy{StatServerAlerts: true, AlertRetentionDays: 42, ReclaimLog: true}
+
+	mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnRows(mock.NewRows([]string{"COUNT"}).AddRow(99))
+	mock.ExpectQuery(q_GetLogSegmentStats).WillReturnError(fmt.Errorf("DbError"))
+	_, err = h.PlanHousekeeping(policy)
+	if err == nil {
+		t.Fatalf("HanaUtilClient.PlanHousekeeping() returned no error")
+	}
+
+	/*Queries are children of the step operations, which are children of the
+	plan*/
+	wantParents := map[string]string{
+		"f_GetStatServerAlerts": "GetStatServerAlerts",
+		"GetStatServerAlerts":   "PlanHousekeeping",
+		"q_GetLogSegmentStats":  "GetLogSegmentStats",
+		"GetLogSegmentStats":    "PlanHousekeeping",
+	}
+	spans := sr.Ended()
+	names := make(map[trace.SpanID]string)
+	for _, s := range spans {
+		names[s.SpanContext().SpanID()] = s.Name()
+	}
+	if len(spans) != len(wantParents)+1 {
+		t.Fatalf("got %d spans, want %d", len(spans), len(wantParents)+1)
+	}
+	for _, s := range spans {
+		if want, ok := wantParents[s.Name()]; ok && names[s.Parent().SpanID()] != want {
+			t.Errorf("span %s is a child of %q, want %s", s.Name(), names[s.Parent().SpanID()], want)
+		}
+	}
+	plan := spans[len(spans)-1]
+	if plan.Name() != "PlanHousekeeping" || plan.Status().Code != codes.Error {
+		t.Errorf("span %s status = %v, want PlanHousekeeping with %v", plan.Name(), plan.Status().Code, codes.Error)
+	}
+
+	/*A failed step marks the apply span failed*/
+	sr = tracetest.NewSpanRecorder()
+	h.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	mock.ExpectQuery(f_GetStatServerAlerts(42)).WillReturnError(fmt.Errorf("DbError"))
+	_, err = h.ApplyHousekeeping(&HousekeepingPlan{policy, []HousekeepingStep{{StepStatServerAlerts, "", 99, 0, false, nil, ""}}})
+	if err == nil {
+		t.Fatalf("HanaUtilClient.ApplyHousekeeping() returned no error")
+	}
+	spans = sr.Ended()
+	apply := spans[len(spans)-1]
+	if apply.Name() != "ApplyHousekeeping" || apply.Status().Code != codes.Error {
+		t.Errorf("span %s status = %v, want ApplyHousekeeping with %v", apply.Name(), apply.Status().Code, codes.Error)
+	}
+	if step := spans[len(spans)-2]; step.Name() != "RemoveStatServerAlerts" || step.Parent().SpanID() != apply.SpanContext().SpanID() {
+		t.Errorf("span %s is not a child of ApplyHousekeeping", step.Name())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHanaUtilClient_QuerySpans(t *testing.T) {
+	db1, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening mock database connection", err)
+	}
+	defer db1.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	h := &HanaUtilClient{db: db1}
+	h.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+
+	/*The statistics read before and after a clear are children of the clear*/
+	cols := []string{"PLAN_COUNT", "PLAN_BYTES", "CAPACITY"}
+	mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(5000, 1024000, 4096000))
+	mock.ExpectExec(q_ClearSqlPlanCache).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(q_GetSqlPlanCacheStats).WillReturnRows(sqlmock.NewRows(cols).AddRow(100, 24000, 4096000))
+	_, err = h.ClearSqlPlanCache()
+	if err != nil {
+		t.Fatalf("HanaUtilClient.ClearSqlPlanCache() error = %v", err)
+	}
+	spans := sr.Ended()
+	clear := spans[len(spans)-1]
+	if clear.Name() != "ClearSqlPlanCache" {
+		t.Fatalf("last span = %s, want ClearSqlPlanCache", clear.Name())
+	}
+	var stats int
+	for _, s := range spans {
+		if s.Name() == "GetSqlPlanCacheStats" {
+			stats++
+			if s.Parent().SpanID() != clear.SpanContext().SpanID() {
+				t.Errorf("span %s is not a child of ClearSqlPlanCache", s.Name())
+			}
+		}
+	}
+	if stats != 2 {
+		t.Errorf("got %d GetSqlPlanCacheStats spans, want 2", stats)
+	}
+
+	/*A query span stays open until its rows are read and records errors
+	found while reading them*/
+	sr = tracetest.NewSpanRecorder()
+	h.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	mock.ExpectQuery(f_GetTraceFiles(0)).WillReturnRows(sqlmock.NewRows([]string{"HOST", "FILE_NAME", "FILE_SIZE", "FILE_MTIME"}).
+		AddRow("hana01", "a.trc", 100, time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)).
+		AddRow("hana01", "b.trc", 100, time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)).
+		RowError(1, fmt.Errorf("DbError")))
+	_, err = h.GetTraceFiles(0)
+	if err == nil {
+		t.Fatalf("HanaUtilClient.GetTraceFiles() returned no error")
+	}
+	spans = sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	q, op := spans[0], spans[1]
+	if q.Name() != "f_GetTraceFiles" || q.Status().Code != codes.Error || len(q.Events()) == 0 {
+		t.Errorf("span %s status = %v with %d events, want f_GetTraceFiles with a recorded error", q.Name(), q.Status().Code, len(q.Events()))
+	}
+	if op.Status().Code != codes.Error {
+		t.Errorf("span %s status = %v, want %v", op.Name(), op.Status().Code, codes.Error)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}